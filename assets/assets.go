@@ -0,0 +1,9 @@
+// Package assets holds the game's images, fonts, sounds, levels, difficulty
+// profiles and translations. They are built into the binary so the game runs from
+// any directory, and in a browser where there is no file system to read them from.
+package assets
+
+import "embed"
+
+//go:embed images fonts sounds levels difficulty lang
+var FS embed.FS
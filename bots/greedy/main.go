@@ -0,0 +1,88 @@
+// Command greedy is an example bot for snake-arena. It heads for the apple
+// and otherwise takes any move that doesn't crash straight away.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+type point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type message struct {
+	Type   string `json:"type"`
+	You    int    `json:"you"`
+	Tick   int    `json:"tick"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Apple  point  `json:"apple"`
+	Snakes []struct {
+		ID        int     `json:"id"`
+		Body      []point `json:"body"`
+		Direction string  `json:"direction"`
+		Alive     bool    `json:"alive"`
+	} `json:"snakes"`
+}
+
+var moves = map[string]point{"up": {0, -1}, "down": {0, 1}, "left": {-1, 0}, "right": {1, 0}}
+
+func main() {
+	var width, height int
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var msg message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			continue
+		}
+		switch msg.Type {
+		case "start":
+			width, height = msg.Width, msg.Height
+		case "state":
+			fmt.Println(msg.Tick, choose(msg, width, height))
+		}
+	}
+}
+
+func choose(msg message, width int, height int) string {
+	taken := map[point]bool{}
+	var head point
+	for _, snake := range msg.Snakes {
+		for idx, cell := range snake.Body {
+			taken[cell] = true
+			if snake.ID == msg.You && idx == 0 {
+				head = cell
+			}
+		}
+	}
+
+	best := ""
+	bestDistance := 0
+	for _, name := range []string{"up", "down", "left", "right"} {
+		move := moves[name]
+		next := point{head.X + move.X, head.Y + move.Y}
+		if next.X < 0 || next.Y < 0 || next.X >= width || next.Y >= height || taken[next] {
+			continue
+		}
+		distance := abs(next.X-msg.Apple.X) + abs(next.Y-msg.Apple.Y)
+		if best == "" || distance < bestDistance {
+			best, bestDistance = name, distance
+		}
+	}
+	if best == "" {
+		return "up" // nothing is safe
+	}
+	return best
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,58 @@
+// Command snake-arena runs a headless tournament between bot programs.
+//
+//	snake-arena -games 5 -seed 1 "go run ./bots/greedy" "python3 mybot.py"
+//
+// Each argument is a command that starts one bot. See package tournament for
+// the protocol bots speak. Results are printed as a table and every match is
+// saved as a replay.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/brantleyr/go-snake/tournament"
+)
+
+func main() {
+	defaults := tournament.DefaultOptions()
+	mode := flag.String("mode", "roundrobin", "roundrobin pairs every bot up, ffa puts them all in one arena")
+	games := flag.Int("games", 1, "seeds to play, per pairing in a round robin")
+	seed := flag.Int64("seed", 1, "seed of the first game, the rest count up from it")
+	width := flag.Int("width", defaults.Width, "board width")
+	height := flag.Int("height", defaults.Height, "board height")
+	maxTicks := flag.Int("max-ticks", defaults.MaxTicks, "ticks before a match goes to the highest score")
+	timeout := flag.Duration("timeout", defaults.Timeout, "how long a bot gets to answer each tick")
+	replays := flag.String("replays", "arena-replays", "directory for match replays, empty to skip them")
+	flag.Parse()
+
+	bots := tournament.ParseBots(flag.Args())
+	if len(bots) < 2 {
+		log.Fatal("give at least two bot commands")
+	}
+
+	t := &tournament.Tournament{
+		Bots:      bots,
+		Games:     *games,
+		Seed:      *seed,
+		Options:   tournament.Options{Width: *width, Height: *height, MaxTicks: *maxTicks, Timeout: *timeout},
+		ReplayDir: *replays,
+		Log:       os.Stderr,
+	}
+
+	start := time.Now()
+	var standings []tournament.Standing
+	var err error
+	if *mode == "ffa" {
+		standings, err = t.FreeForAll()
+	} else {
+		standings, err = t.RoundRobin()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Done in %s", time.Since(start).Round(time.Millisecond))
+	tournament.WriteTable(os.Stdout, standings)
+}
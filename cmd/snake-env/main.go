@@ -0,0 +1,19 @@
+// Command snake-env runs the snake environment over stdin and stdout, one
+// JSON request and one JSON response per line. See package snakeenv for the
+// requests. A trainer starts it as a subprocess:
+//
+//	proc = subprocess.Popen(["snake-env"], stdin=PIPE, stdout=PIPE)
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/brantleyr/go-snake/snakeenv"
+)
+
+func main() {
+	if err := snakeenv.Serve(os.Stdin, os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
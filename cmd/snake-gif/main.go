@@ -0,0 +1,34 @@
+// Command snake-gif turns a saved replay into an animated GIF without
+// opening a window, so it runs on a server or in CI.
+//
+//	snake-gif --export-gif run.replay.json out.gif
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/brantleyr/go-snake/replay"
+	"github.com/brantleyr/go-snake/replaygif"
+)
+
+func main() {
+	exportGIF := flag.String("export-gif", "", "the replay to render: --export-gif run.replay.json out.gif")
+	cell := flag.Int("cell", replaygif.DefaultCellSize, "pixels a side for each cell")
+	flag.Parse()
+	if *exportGIF == "" {
+		log.Fatal("usage: snake-gif --export-gif run.replay.json out.gif")
+	}
+
+	out := flag.Arg(0)
+	if out == "" {
+		out = "replay.gif"
+	}
+	run, err := replay.Load(*exportGIF)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := replaygif.Write(run, out, *cell); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,27 @@
+// Command snake-leaderboard runs a shared leaderboard for go-snake.
+//
+//	snake-leaderboard -addr :8090 -data scores.json
+//
+// then start the game with --leaderboard http://<host>:8090
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/brantleyr/go-snake/leaderboard"
+)
+
+func main() {
+	addr := flag.String("addr", ":"+leaderboard.DefaultPort, "address to listen on")
+	data := flag.String("data", "leaderboard.json", "file the scores are kept in")
+	flag.Parse()
+
+	server, err := leaderboard.NewServer(*data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Leaderboard listening on %s, scores in %s", *addr, *data)
+	log.Fatal(http.ListenAndServe(*addr, server))
+}
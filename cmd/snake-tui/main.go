@@ -0,0 +1,47 @@
+// Command snake-tui plays snake in the terminal, no window needed.
+//
+//	snake-tui                    play with the arrow keys or WASD
+//	snake-tui -difficulty hard   play one of the game's difficulties
+//	snake-tui -bot -ticks 300    let a bot play, for smoke tests
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/brantleyr/go-snake/assets"
+	"github.com/brantleyr/go-snake/rules"
+	"github.com/brantleyr/go-snake/tui"
+)
+
+func main() {
+	seed := flag.Int64("seed", time.Now().UnixNano(), "seed for the apples")
+	difficulty := flag.String("difficulty", "normal", "difficulty to play, one of the profiles in assets/difficulty")
+	bot := flag.Bool("bot", false, "let a bot play")
+	ticks := flag.Int("ticks", 0, "quit after this many moves, 0 for no limit")
+	flag.Parse()
+
+	profiles, err := rules.LoadProfiles(assets.FS, "difficulty")
+	if err != nil {
+		log.Fatal(err)
+	}
+	var profile *rules.Profile
+	ids := []string{}
+	for idx := range profiles {
+		if profiles[idx].ID == *difficulty {
+			profile = &profiles[idx]
+		}
+		ids = append(ids, profiles[idx].ID)
+	}
+	if profile == nil {
+		log.Fatalf("no difficulty %q, pick one of %s", *difficulty, strings.Join(ids, ", "))
+	}
+
+	err = tui.Run(tui.Options{Profile: *profile, Seed: *seed, Bot: *bot, MaxTicks: *ticks, In: os.Stdin, Out: os.Stdout})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
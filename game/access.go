@@ -0,0 +1,172 @@
+package game
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+
+	"github.com/brantleyr/go-snake/layout"
+)
+
+const (
+	standardPalette = "standard"
+	minGameSpeed    = 50 // percent of full speed
+	gameSpeedStep   = 10
+)
+
+// Palettes in the order the settings cycle through them. The others recolor
+// the green, orange and red snakes, and mark the faster ones with dots so the
+// speed doesn't come down to telling colors apart.
+var (
+	paletteChoices = []string{standardPalette, "red_green", "greyscale"}
+	paletteColors  = map[string]map[string]string{
+		// Blue, orange and purple from the Okabe-Ito palette
+		"red_green": {"green": "#0072b2", "orange": "#e69f00", "red": "#cc79a7"},
+		"greyscale": {"green": "#ffffff", "orange": "#a0a0a0", "red": "#606060"},
+	}
+	tierMarks = map[string]int{"green": 0, "orange": 1, "red": 2}
+)
+
+var (
+	snakePalette  = standardPalette
+	highContrast  = false // a plain black and grey board with a white border
+	reducedMotion = false // no background zoom, body wobble or apple pulse
+	gameSpeed     = 100   // percent, lower plays slower
+
+	gameSpeedCarry int
+	snakeLayer     *ebiten.Image
+)
+
+// simulationFrame is whether the game moves on this frame. Below full
+// speed it skips frames, so everything slows down together and a run is the
+// same moves as it would be at full speed.
+func simulationFrame() bool {
+	gameSpeedCarry += gameSpeed
+	if gameSpeedCarry < 100 {
+		return false
+	}
+	gameSpeedCarry -= 100
+	return true
+}
+
+// beginSnakeColors gives back where to draw a snake of colorName, and which
+// sprites to draw it with. Other palettes draw the green sprites on a layer
+// that endSnakeColors recolors.
+func beginSnakeColors(screen *ebiten.Image, colorName string) (*ebiten.Image, string) {
+	if snakePalette == standardPalette {
+		return screen, colorName
+	}
+	if snakeLayer == nil || snakeLayer.Bounds().Size() != screen.Bounds().Size() {
+		snakeLayer = ebiten.NewImage(screen.Bounds().Dx(), screen.Bounds().Dy())
+	}
+	snakeLayer.Clear()
+	return snakeLayer, "green"
+}
+
+// endSnakeColors puts the recolored snake on the screen, with its tier's
+// marks on cells
+func endSnakeColors(screen *ebiten.Image, colorName string, cells []image.Point) {
+	if snakePalette == standardPalette {
+		return
+	}
+	clr := ParseHexColor(paletteColors[snakePalette][colorName])
+	op := &ebiten.DrawImageOptions{}
+	// Grey the sprites, then brighten them back up to the palette's color
+	op.ColorM.ChangeHSV(0, 0, 1)
+	op.ColorM.Scale(1.5*float64(clr.R)/0xff, 1.5*float64(clr.G)/0xff, 1.5*float64(clr.B)/0xff, 1)
+	screen.DrawImage(snakeLayer, op)
+
+	marks := tierMarks[colorName]
+	if marks == 0 {
+		return
+	}
+	radius := float64(gridCellWidth) / 8
+	for _, cell := range cells {
+		centerX := float64(cell.X*gridCellWidth+borderLeft) + float64(gridCellWidth)/2
+		centerY := float64(cell.Y*gridCellHeight+borderTop) + float64(gridCellHeight)/2
+		for mark := 0; mark < marks; mark++ {
+			offset := (float64(mark) - float64(marks-1)/2) * radius * 2.5
+			ebitenutil.DrawCircle(screen, centerX+offset, centerY, radius, color.Black)
+		}
+	}
+}
+
+func paletteIndex() int {
+	for idx, choice := range paletteChoices {
+		if choice == snakePalette {
+			return idx
+		}
+	}
+	return 0
+}
+
+// accessibilityScene opens from the settings, in their place
+type accessibilityScene struct {
+	menu *menu
+}
+
+func (s *accessibilityScene) Enter() {
+	s.menu = newMenu(accessibilityItems()...)
+}
+
+func (s *accessibilityScene) Exit() {}
+
+func accessibilityItems() []menuItem {
+	onOff := func(on bool) string {
+		if on {
+			return tr("settings.on")
+		}
+		return tr("settings.off")
+	}
+	nextPalette := func(step int) {
+		snakePalette = paletteChoices[(paletteIndex()+len(paletteChoices)+step)%len(paletteChoices)]
+		saveSettings()
+	}
+	toggleContrast := func() {
+		highContrast = !highContrast
+		saveSettings()
+	}
+	toggleMotion := func() {
+		reducedMotion = !reducedMotion
+		saveSettings()
+	}
+	changeSpeed := func(step int) {
+		gameSpeed += step * gameSpeedStep
+		if gameSpeed < minGameSpeed {
+			gameSpeed = minGameSpeed
+		}
+		if gameSpeed > 100 {
+			gameSpeed = 100
+		}
+		saveSettings()
+	}
+	return []menuItem{
+		{label: tr("access.palette", tr("palette."+snakePalette)), action: func() { nextPalette(1) }, adjust: nextPalette},
+		{label: tr("access.high_contrast", onOff(highContrast)), action: toggleContrast, adjust: func(int) { toggleContrast() }},
+		{label: tr("access.reduced_motion", onOff(reducedMotion)), action: toggleMotion, adjust: func(int) { toggleMotion() }},
+		{label: tr("access.game_speed", num(gameSpeed)), action: func() {
+			// Enter steps down and then back round to full speed
+			if gameSpeed == minGameSpeed {
+				changeSpeed(100)
+			} else {
+				changeSpeed(-1)
+			}
+		}, adjust: changeSpeed},
+		{label: tr("menu.back"), action: popScene},
+	}
+}
+
+func (s *accessibilityScene) Update(g *Game) error {
+	if s.menu.update() {
+		popScene()
+	}
+	s.menu.setItems(accessibilityItems())
+	return nil
+}
+
+func (s *accessibilityScene) Draw(g *Game, screen *ebiten.Image) {
+	drawn := s.menu.draw(screen, baseFont, runMenuArea(), 40)
+	drawText(screen, tr("settings.keys"), timerFont, under(drawn), layout.Top, ParseHexColor("#8c8c8c"))
+}
@@ -0,0 +1,92 @@
+package game
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Control schemes in the order the settings cycle through them. Absolute
+// points the snake up, down, left or right, turn only turns it left or right
+// of where it's heading, which needs two keys and doesn't change when the
+// snake is coming down the screen.
+var controlChoices = []string{"absolute", "turn"}
+
+var (
+	controls = "absolute"
+
+	// snakeHeading is the way the snake last moved, turns are from it so
+	// two quick turns can't fold the snake back into itself
+	snakeHeading string
+)
+
+var (
+	leftOf  = map[string]string{"up": "left", "left": "down", "down": "right", "right": "up"}
+	rightOf = map[string]string{"up": "right", "right": "down", "down": "left", "left": "up"}
+)
+
+// steerPressed is the direction the player just asked for, or empty. It's
+// always an absolute direction, whatever the controls, so runs and replays
+// don't care which scheme was played with. current is where the snake is set
+// to go next and heading is where it last went.
+func steerPressed(current, heading string) string {
+	if controls == "turn" {
+		if turnLeftPressed() {
+			return leftOf[heading]
+		}
+		if turnRightPressed() {
+			return rightOf[heading]
+		}
+		return ""
+	}
+
+	next := ""
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyUp) || inpututil.IsKeyJustPressed(ebiten.KeyW) ||
+		gamepadJustPressed(ebiten.StandardGamepadButtonLeftTop):
+		next = "up"
+	case inpututil.IsKeyJustPressed(ebiten.KeyDown) || inpututil.IsKeyJustPressed(ebiten.KeyS) ||
+		gamepadJustPressed(ebiten.StandardGamepadButtonLeftBottom):
+		next = "down"
+	case inpututil.IsKeyJustPressed(ebiten.KeyLeft) || inpututil.IsKeyJustPressed(ebiten.KeyA) ||
+		gamepadJustPressed(ebiten.StandardGamepadButtonLeftLeft):
+		next = "left"
+	case inpututil.IsKeyJustPressed(ebiten.KeyRight) || inpututil.IsKeyJustPressed(ebiten.KeyD) ||
+		gamepadJustPressed(ebiten.StandardGamepadButtonLeftRight):
+		next = "right"
+	}
+	// The snake can't go straight back the way it last went, whatever turn is waiting
+	if next == current || leftOf[leftOf[next]] == heading {
+		return ""
+	}
+	return next
+}
+
+// Turns take the left and right keys, the d-pad and the shoulder buttons
+func turnLeftPressed() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyLeft) || inpututil.IsKeyJustPressed(ebiten.KeyA) ||
+		gamepadJustPressed(ebiten.StandardGamepadButtonLeftLeft) || gamepadJustPressed(ebiten.StandardGamepadButtonFrontTopLeft)
+}
+
+func turnRightPressed() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyRight) || inpututil.IsKeyJustPressed(ebiten.KeyD) ||
+		gamepadJustPressed(ebiten.StandardGamepadButtonLeftRight) || gamepadJustPressed(ebiten.StandardGamepadButtonFrontTopRight)
+}
+
+// pausePressed is Escape or a gamepad's start button
+func pausePressed() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyEscape) || gamepadJustPressed(ebiten.StandardGamepadButtonCenterRight)
+}
+
+func controlsIndex() int {
+	for idx, choice := range controlChoices {
+		if choice == controls {
+			return idx
+		}
+	}
+	return 0
+}
+
+// controlsHelp is the line of the start text that says how to steer
+func controlsHelp() string {
+	return tr("controls.help_" + controls)
+}
@@ -0,0 +1,253 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"log"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"github.com/brantleyr/go-snake/layout"
+	"github.com/brantleyr/go-snake/storage"
+)
+
+const (
+	dailyDateFormat  = "2006-01-02"
+	dailyHistoryFile = "daily-history.json"
+	goldenAppleOdds  = 5 // one in this many apples is golden
+	goldenAppleScore = 3
+	dailyMargin      = 50 // between the calendar and the sides of the screen
+)
+
+// dailyRules is everything that makes up one day's challenge. It is built
+// from nothing but the date, so every copy of the game agrees on it offline.
+type dailyRules struct {
+	date         string
+	seed         int64
+	layout       *level // nil is the open board
+	wrap         bool
+	goldenApples bool
+	fastStart    bool
+}
+
+type dailyResult struct {
+	Score   int `json:"score"`
+	Seconds int `json:"seconds"`
+}
+
+var (
+	dailyToday     dailyRules
+	dailyHistory   = map[string]dailyResult{}
+	dailyMonth     time.Time // month shown on the calendar
+	wrapWalls      = false
+	goldenApples   = false
+	nomGolden      = false
+	runSeed        int64
+	gameRand       = rand.New(rand.NewSource(time.Now().UnixNano()))
+	dailyCompleted = false // the current daily run has been written to the history
+)
+
+// dailyDate is today's date in UTC, so players in different time zones
+// are on the same challenge at the same moment
+func dailyDate() string {
+	return time.Now().UTC().Format(dailyDateFormat)
+}
+
+func buildDailyRules(date string) dailyRules {
+	hash := fnv.New64a()
+	hash.Write([]byte("go-snake daily " + date))
+	rules := dailyRules{date: date, seed: int64(hash.Sum64())}
+
+	// A separate source for the rules, so picking them doesn't use up
+	// numbers from the apple sequence
+	pick := rand.New(rand.NewSource(rules.seed))
+	if layout := pick.Intn(len(levels) + 1); layout > 0 {
+		rules.layout = levels[layout-1]
+	}
+	rules.wrap = pick.Intn(2) == 0
+	rules.goldenApples = pick.Intn(2) == 0
+	rules.fastStart = pick.Intn(3) == 0
+	if !rules.wrap && !rules.goldenApples && !rules.fastStart {
+		rules.goldenApples = true
+	}
+
+	return rules
+}
+
+func (rules dailyRules) describe() string {
+	layout := tr("daily.open_board")
+	if rules.layout != nil {
+		layout = rules.layout.name
+	}
+	modifiers := []string{}
+	if rules.wrap {
+		modifiers = append(modifiers, tr("daily.wrap"))
+	}
+	if rules.goldenApples {
+		modifiers = append(modifiers, tr("daily.golden"))
+	}
+	if rules.fastStart {
+		modifiers = append(modifiers, tr("daily.fast_start"))
+	}
+	return layout + "\n" + strings.Join(modifiers, " + ")
+}
+
+// seedRun gets the random numbers for a new run ready. The daily challenge
+// always starts from the same seed, other modes start somewhere new.
+func seedRun() {
+	runSeed = time.Now().UnixNano()
+	wrapWalls = false
+	goldenApples = false
+	if GameState == "game_daily" {
+		runSeed = dailyToday.seed
+		wrapWalls = dailyToday.wrap
+		goldenApples = dailyToday.goldenApples
+		dailyCompleted = false
+	}
+	gameRand = rand.New(rand.NewSource(runSeed))
+	nomActive = false
+	nomGolden = false
+	startRun()
+}
+
+// startDailySpeed bumps the starting speed for the fast start modifier
+func startDailySpeed() {
+	if GameState == "game_daily" && dailyToday.fastStart {
+		runRamp.Speed = 16
+		runRamp.Shown = 3
+	}
+}
+
+// wrapHead moves the head to the opposite side when it goes off the board
+func wrapHead() {
+	if snakePlayer.xPos < 0 {
+		snakePlayer.xPos = gridWidth - 1
+	} else if snakePlayer.xPos >= gridWidth {
+		snakePlayer.xPos = 0
+	}
+	if snakePlayer.yPos < 0 {
+		snakePlayer.yPos = gridHeight - 1
+	} else if snakePlayer.yPos >= gridHeight {
+		snakePlayer.yPos = 0
+	}
+}
+
+// nomScore is how much the apple being eaten is worth
+func nomScore() int {
+	if nomGolden {
+		return goldenAppleScore
+	}
+	return 1
+}
+
+func loadDailyHistory() {
+	data, err := store.Load(dailyHistoryFile)
+	if errors.Is(err, storage.ErrNotFound) {
+		return
+	}
+	if err != nil {
+		log.Println("daily history:", err)
+		return
+	}
+	if err := json.Unmarshal(data, &dailyHistory); err != nil {
+		log.Println("daily history:", err)
+	}
+}
+
+func saveDailyHistory() {
+	data, err := json.MarshalIndent(dailyHistory, "", "  ")
+	if err != nil {
+		log.Println("daily history:", err)
+		return
+	}
+	if err := store.Save(dailyHistoryFile, data); err != nil {
+		log.Println("daily history:", err)
+	}
+}
+
+// recordDailyResult keeps the best result for the day the run was for
+func recordDailyResult() {
+	// Runs changed from the console have no replay left and don't count
+	if GameState != "game_daily" || dailyCompleted || currentRun == nil {
+		return
+	}
+	dailyCompleted = true
+
+	best, played := dailyHistory[dailyToday.date]
+	if played && (best.Score > currScore || (best.Score == currScore && best.Seconds >= runRamp.Clock.Seconds())) {
+		return
+	}
+	dailyHistory[dailyToday.date] = dailyResult{currScore, runRamp.Clock.Seconds()}
+	saveDailyHistory()
+}
+
+// enterDaily builds today's challenge and opens the calendar screen
+func enterDaily() {
+	dailyToday = buildDailyRules(dailyDate())
+	now := time.Now().UTC()
+	dailyMonth = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	showScreen("daily")
+}
+
+func handleDailyKeys() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		dailyLevel = dailyToday.layout
+		showScreen("game_daily")
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		showScreen("title")
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) || inpututil.IsKeyJustPressed(ebiten.KeyA) {
+		dailyMonth = dailyMonth.AddDate(0, -1, 0)
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) || inpututil.IsKeyJustPressed(ebiten.KeyD) {
+		dailyMonth = dailyMonth.AddDate(0, 1, 0)
+	}
+}
+
+// doDaily shows today's rules and a calendar of past results
+func doDaily(g *Game, screen *ebiten.Image) {
+	drawBg(screen)
+	drawBlackOverlay(screen)
+
+	drawn := drawHeading(screen, tr("daily.heading", dailyToday.date))
+	drawn = drawText(screen, dailyToday.describe(), timerFont, under(drawn), layout.Top, ParseHexColor("#749e35"))
+	if best, ok := dailyHistory[dailyToday.date]; ok {
+		drawn = drawText(screen, trn("daily.best", best.Score, num(best.Score), num(best.Seconds)), timerFont, under(drawn), layout.Top, color.White)
+	}
+
+	// Calendar, a column a weekday with a row of cells a week under the names
+	month := tr("daily.month", tr("month."+strconv.Itoa(int(dailyMonth.Month()))), dailyMonth.Year())
+	drawn = drawText(screen, "< "+month+" >", baseFont, layout.Inset(under(drawn), textPadding), layout.Top, color.White)
+	columns := layout.Columns(layout.Inset(under(drawn), dailyMargin), 7)
+	for idx, column := range columns {
+		drawn = drawn.Union(drawText(screen, tr("weekday."+strconv.Itoa(idx)), timerFont, column, layout.TopLeft, ParseHexColor("#8c8c8c")))
+	}
+	top := drawn.Max.Y
+	cellHeight := layout.LineHeight(timerFont) + textPadding
+
+	offset := int(dailyMonth.Weekday())
+	for day := dailyMonth; day.Month() == dailyMonth.Month(); day = day.AddDate(0, 0, 1) {
+		slot := offset + day.Day() - 1
+		column := columns[slot%7]
+		row := top + (slot/7)*cellHeight
+		cell := image.Rect(column.Min.X, row, column.Max.X, row+cellHeight)
+
+		date := day.Format(dailyDateFormat)
+		dayColor := ParseHexColor("#8c8c8c")
+		if date == dailyToday.date {
+			dayColor = color.RGBA{0xff, 0xff, 0xff, 0xff}
+		}
+		drawText(screen, strconv.Itoa(day.Day()), timerFont, cell, layout.TopLeft, dayColor)
+		if result, ok := dailyHistory[date]; ok {
+			drawText(screen, num(result.Score), scoreFont, cell, layout.TopRight, ParseHexColor("#ffd700"))
+		}
+	}
+
+	drawFooter(screen, tr("daily.keys"))
+}
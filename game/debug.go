@@ -0,0 +1,213 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	debugPathShown  = 24 // snakePath entries listed before "..."
+	debugLineHeight = 16
+	consoleHistory  = 6
+)
+
+var (
+	// DebugMode lets F3 and the backquote key open the overlay and console
+	DebugMode = false
+
+	showDebug    = false
+	consoleOpen  = false
+	consoleInput string
+	consoleLog   []string
+	godMode      = false
+)
+
+// consoleCommands change the live game, for trying out edge cases quickly
+var consoleCommands = map[string]func(args []string) string{
+	"help": func(args []string) string {
+		return "speed N, grow N, spawn apple X Y, god, seed N"
+	},
+	"speed": func(args []string) string {
+		speed, err := intArgs(args, 1)
+		if err != nil {
+			return "usage: speed N"
+		}
+		setPracticeSpeed(speed[0])
+		return fmt.Sprintf("speed %d, clockSpeed %d", runRamp.Shown, runRamp.Speed)
+	},
+	"grow": func(args []string) string {
+		count, err := intArgs(args, 1)
+		if err != nil || count[0] < 1 {
+			return "usage: grow N"
+		}
+		growSnake(count[0])
+		return fmt.Sprintf("length %d", len(snakePlayer.snakeBody))
+	},
+	"spawn": func(args []string) string {
+		if len(args) != 3 || args[0] != "apple" {
+			return "usage: spawn apple X Y"
+		}
+		cell, err := intArgs(args[1:], 2)
+		if err != nil || cell[0] < 0 || cell[0] >= gridWidth || cell[1] < 0 || cell[1] >= gridHeight {
+			return fmt.Sprintf("X and Y go from 0,0 to %d,%d", gridWidth-1, gridHeight-1)
+		}
+		currentNom = pathPair{cell[0], cell[1], "", false}
+		nomActive = true
+		nomGolden = false
+		return fmt.Sprintf("apple at %d,%d", cell[0], cell[1])
+	},
+	"god": func(args []string) string {
+		godMode = !godMode
+		if godMode {
+			return "god mode on, nothing ends the run"
+		}
+		return "god mode off"
+	},
+	"seed": func(args []string) string {
+		if len(args) != 1 {
+			return "usage: seed N"
+		}
+		seed, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return "usage: seed N"
+		}
+		runSeed = seed
+		gameRand = rand.New(rand.NewSource(seed))
+		return fmt.Sprintf("apples now come from seed %d", seed)
+	},
+}
+
+func intArgs(args []string, count int) ([]int, error) {
+	if len(args) != count {
+		return nil, fmt.Errorf("want %d numbers", count)
+	}
+	numbers := []int{}
+	for _, arg := range args {
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, err
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, nil
+}
+
+// growSnake adds pieces to the tail. The path gets the tail cell repeated
+// so there is somewhere to draw them until the snake moves off it.
+func growSnake(count int) {
+	tail := snakePath[len(snakePath)-1]
+	for idx := 0; idx < count; idx++ {
+		snakePlayer.snakeBody = append([]snakeBody{{tail.xPos, tail.yPos, len(snakePlayer.snakeBody)}}, snakePlayer.snakeBody...)
+		snakePath = append(snakePath, tail)
+	}
+}
+
+// handleDebugKeys toggles the overlay and the console. It reports whether
+// the console has the keyboard, in which case nothing else should see the keys.
+func handleDebugKeys() bool {
+	if !DebugMode {
+		return false
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF3) {
+		showDebug = !showDebug
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyGraveAccent) {
+		consoleOpen = !consoleOpen
+		consoleInput = ""
+		// Stop the snake while typing
+		if consoleOpen && gameStarted() && !gamePaused() {
+			openRunMenu()
+		}
+		return true
+	}
+	if !consoleOpen {
+		return false
+	}
+
+	for _, r := range ebiten.AppendInputChars(nil) {
+		if r != '`' {
+			consoleInput += string(r)
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(consoleInput) > 0 {
+		consoleInput = consoleInput[:len(consoleInput)-1]
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		consoleOpen = false
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		runConsoleCommand(consoleInput)
+		consoleInput = ""
+	}
+	return true
+}
+
+func runConsoleCommand(line string) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return
+	}
+	reply := "unknown command, try help"
+	if command, ok := consoleCommands[fields[0]]; ok {
+		reply = command(fields[1:])
+		// A run that's been tampered with isn't a real score
+		if fields[0] != "help" {
+			currentRun = nil
+		}
+	}
+	consoleLog = append(consoleLog, "> "+line, reply)
+	if len(consoleLog) > consoleHistory {
+		consoleLog = consoleLog[len(consoleLog)-consoleHistory:]
+	}
+}
+
+// drawDebug draws the overlay and the console over everything else
+func drawDebug(g *Game, screen *ebiten.Image) {
+	if showDebug {
+		lines := []string{
+			fmt.Sprintf("FPS %.1f  TPS %.1f  goroutines %d", ebiten.ActualFPS(), ebiten.ActualTPS(), runtime.NumGoroutine()),
+			fmt.Sprintf("state %s  clockSpeed %d  clockSpeedCount %d  clock %dms", GameState, runRamp.Speed, g.clockSpeedCount, runRamp.Clock.Millis()),
+			fmt.Sprintf("head %d,%d %s", snakePlayer.xPos, snakePlayer.yPos, snakePlayer.direction),
+			fmt.Sprintf("apple %d,%d active %t", currentNom.xPos, currentNom.yPos, nomActive),
+			fmt.Sprintf("snakePath (%d)", len(snakePath)),
+		}
+		cells := []string{}
+		for idx, pair := range snakePath {
+			if idx == debugPathShown {
+				cells = append(cells, fmt.Sprintf("... %d more", len(snakePath)-idx))
+				break
+			}
+			cells = append(cells, fmt.Sprintf("%d,%d", pair.xPos, pair.yPos))
+		}
+		for start := 0; start < len(cells); start += 8 {
+			end := start + 8
+			if end > len(cells) {
+				end = len(cells)
+			}
+			lines = append(lines, "  "+strings.Join(cells[start:end], " "))
+		}
+		if godMode {
+			lines = append(lines, "GOD MODE")
+		}
+		ebitenutil.DrawRect(screen, borderLeft, borderTop, 420, float64(len(lines)*debugLineHeight+8), ParseHexColorAlpha("#000000", 0xbb))
+		for idx, line := range lines {
+			ebitenutil.DebugPrintAt(screen, line, borderLeft+4, borderTop+4+idx*debugLineHeight)
+		}
+	}
+
+	if consoleOpen {
+		lines := append(append([]string{}, consoleLog...), "> "+consoleInput+"_")
+		top := ScreenHeight - borderBottom - len(lines)*debugLineHeight - 8
+		ebitenutil.DrawRect(screen, 0, float64(top), float64(ScreenWidth), float64(ScreenHeight-top), ParseHexColorAlpha("#000000", 0xdd))
+		for idx, line := range lines {
+			ebitenutil.DebugPrintAt(screen, line, borderLeft, top+4+idx*debugLineHeight)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package game
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/brantleyr/go-snake/assets"
+	"github.com/brantleyr/go-snake/layout"
+	"github.com/brantleyr/go-snake/replay"
+	"github.com/brantleyr/go-snake/rules"
+)
+
+const (
+	difficultyDir    = "difficulty"
+	customDifficulty = "custom"
+)
+
+// difficulty is a profile from assets/difficulty, a speed curve and the
+// speeds where the snake changes color
+type difficulty struct {
+	rules.Profile
+}
+
+// difficultyParam is one line of the Custom screen. field is its name in the
+// JSON, and its message is "difficulty." and the field.
+type difficultyParam struct {
+	field    string
+	value    func(d *difficulty) *int
+	min, max int
+	step     int
+}
+
+var difficultyParams = []difficultyParam{
+	{"start_speed", func(d *difficulty) *int { return &d.StartSpeed }, 3, 40, 1},
+	{"step", func(d *difficulty) *int { return &d.Step }, 1, 10, 1},
+	{"interval", func(d *difficulty) *int { return &d.Interval }, 1, 50, 1},
+	{"floor", func(d *difficulty) *int { return &d.Floor }, 1, 40, 1},
+	{"grace", func(d *difficulty) *int { return &d.Grace }, 0, 10000, 250},
+	{"orange_at", func(d *difficulty) *int { return &d.OrangeAt }, 1, 30, 1},
+	{"red_at", func(d *difficulty) *int { return &d.RedAt }, 1, 30, 1},
+}
+
+var (
+	difficulties       []*difficulty // the presets in file order, then Custom
+	selectedDifficulty = "normal"    // what New Game plays
+	difficultyMenu     = newMenu()
+)
+
+func loadDifficulties() {
+	profiles, err := rules.LoadProfiles(assets.FS, difficultyDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, profile := range profiles {
+		d := &difficulty{profile}
+		if err := d.check(); err != nil {
+			log.Fatalf("%s difficulty: %v", d.ID, err)
+		}
+		difficulties = append(difficulties, d)
+	}
+	if difficultyByID("normal").ID != "normal" {
+		log.Fatal("there has to be a normal difficulty")
+	}
+
+	// Custom starts out as Normal until the player changes it
+	custom := *difficultyByID("normal")
+	custom.ID = customDifficulty
+	custom.Name = "Custom"
+	difficulties = append(difficulties, &custom)
+
+	// Every profile keeps its own scores, Custom's tab shows its current numbers
+	modes := []string{}
+	for _, d := range difficulties {
+		modes = append(modes, d.ID)
+	}
+	leaderboardModes = append(modes, leaderboardModes...)
+	leaderboardTab = selectedDifficultyIndex()
+}
+
+func (d *difficulty) check() error {
+	if d.ID == "" || d.Name == "" {
+		return fmt.Errorf("needs an id and a name")
+	}
+	for _, param := range difficultyParams {
+		if value := *param.value(d); value < param.min || value > param.max {
+			return fmt.Errorf("%s is %d, it goes from %d to %d", param.field, value, param.min, param.max)
+		}
+	}
+	if d.Floor > d.StartSpeed {
+		return fmt.Errorf("the fastest speed is slower than the start")
+	}
+	return nil
+}
+
+// difficultyByID finds a profile, falling back to Normal
+func difficultyByID(id string) *difficulty {
+	for _, d := range difficulties {
+		if d.ID == id {
+			return d
+		}
+	}
+	for _, d := range difficulties {
+		if d.ID == "normal" {
+			return d
+		}
+	}
+	return &difficulty{}
+}
+
+// mode is what runs on the profile go by in replays and score tables. Each
+// set of Custom numbers is its own mode, so their scores don't mix.
+func (d *difficulty) mode() string {
+	if d.ID == customDifficulty {
+		return replay.CustomMode(d.Curve)
+	}
+	return d.ID
+}
+
+// difficultyForMode is the profile runs in mode were played on, or nil for
+// the modes that aren't a difficulty. Custom numbers since changed still
+// count as Custom.
+func difficultyForMode(mode string) *difficulty {
+	if replay.IsCustomMode(mode) {
+		return difficultyByID(customDifficulty)
+	}
+	for _, d := range difficulties {
+		if d.ID == mode && d.ID != customDifficulty {
+			return d
+		}
+	}
+	return nil
+}
+
+// playReplayDifficulty sets New Game to the profile a replay was played on,
+// a custom run bringing its numbers along
+func playReplayDifficulty(run *replay.Replay) {
+	d := difficultyForMode(run.Mode)
+	if d == nil {
+		return
+	}
+	if d.ID == customDifficulty {
+		d.Curve = run.Curve
+	}
+	selectedDifficulty = d.ID
+}
+
+// activeDifficulty is the profile the current mode plays with. New Game
+// plays the one picked on the Difficulty screen, other modes play Normal.
+func activeDifficulty() *difficulty {
+	if GameState == "game" {
+		return difficultyByID(selectedDifficulty)
+	}
+	return difficultyByID("normal")
+}
+
+// difficultyName is the profile's name in the current language. Profiles
+// added without a translation go by the name in their file.
+func difficultyName(d *difficulty) string {
+	key := "difficulty." + d.ID
+	if name := tr(key); name != key {
+		return name
+	}
+	return d.Name
+}
+
+func selectedDifficultyIndex() int {
+	for idx, d := range difficulties {
+		if d.ID == selectedDifficulty {
+			return idx
+		}
+	}
+	return 0
+}
+
+// difficultyItems are the profile, then a line for each number, then Done.
+// Changing a preset's number turns it into Custom, starting from the
+// preset's numbers.
+func difficultyItems() []menuItem {
+	d := difficultyByID(selectedDifficulty)
+	done := func() {
+		saveSettings()
+		showScreen("title")
+	}
+	items := []menuItem{{label: "<  " + difficultyName(d) + "  >", action: done, adjust: func(step int) {
+		selected := (selectedDifficultyIndex() + len(difficulties) + step) % len(difficulties)
+		selectedDifficulty = difficulties[selected].ID
+	}}}
+	for _, param := range difficultyParams {
+		param := param
+		items = append(items, menuItem{
+			label:  tr("difficulty."+param.field, num(*param.value(d))),
+			action: done,
+			adjust: func(step int) { changeCustom(param, step) },
+		})
+	}
+	return append(items, menuItem{label: tr("menu.done"), action: done})
+}
+
+func changeCustom(param difficultyParam, step int) {
+	custom := difficultyByID(customDifficulty)
+	if selectedDifficulty != customDifficulty {
+		*custom = *difficultyByID(selectedDifficulty)
+		custom.ID = customDifficulty
+		custom.Name = "Custom"
+		selectedDifficulty = customDifficulty
+	}
+	value := param.value(custom)
+	*value += step * param.step
+	if *value < param.min {
+		*value = param.min
+	}
+	if *value > param.max {
+		*value = param.max
+	}
+	if custom.Floor > custom.StartSpeed {
+		custom.Floor = custom.StartSpeed
+	}
+}
+
+func handleDifficultyKeys() {
+	difficultyMenu.setItems(difficultyItems())
+	if difficultyMenu.update() {
+		saveSettings()
+		showScreen("title")
+	}
+	// The labels show the numbers, so they change with them
+	difficultyMenu.setItems(difficultyItems())
+}
+
+func doDifficulty(g *Game, screen *ebiten.Image) {
+	drawBg(screen)
+	drawBlackOverlay(screen)
+
+	heading := drawHeading(screen, tr("difficulty.heading"))
+	drawn := difficultyMenu.draw(screen, timerFont, layout.Inset(under(heading), textPadding), 44)
+
+	drawText(screen, tr("difficulty.note"), timerFont, under(drawn), layout.Top, ParseHexColor("#749e35"))
+	drawFooter(screen, tr("difficulty.keys"))
+}
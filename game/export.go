@@ -0,0 +1,320 @@
+package game
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"os"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/brantleyr/go-snake/replay"
+	"github.com/brantleyr/go-snake/rules"
+	"github.com/brantleyr/go-snake/simclock"
+)
+
+const (
+	gifClipSeconds   = 10
+	gifClipScale     = 0.5
+	gifWholeScale    = 0.25 // whole runs get long, keep them small
+	gifFramesPerDraw = 20   // spread rendering out so the game keeps drawing
+	centisPerSecond  = 100
+)
+
+// runFrame is everything the playfield is drawn from, saved once a move so
+// the end of a run can be drawn again later with the same code as doGame
+type runFrame struct {
+	xPos, yPos int
+	direction  string
+	path       []pathPair
+	bodyLen    int
+	nom        pathPair
+	nomActive  bool
+	nomGolden  bool
+	hazards    []*hazard
+	score      int
+	speed      int
+	frames     int // on the game clock
+	shrinkRing int
+	delay      int // hundredths of a second until the next move
+	at         int // hundredths of a second since the run started
+}
+
+// gifJob renders frames offscreen a few at a time and then writes the GIF
+type gifJob struct {
+	frames []runFrame
+	next   int
+	scale  float64
+	path   string
+	images []*image.Paletted
+	delays []int
+	colors map[color.RGBA]uint8
+	board  *ebiten.Image
+	small  *ebiten.Image
+}
+
+var (
+	runFrames  []runFrame
+	exportJob  *gifJob
+	gifStatus  string
+	gifResults = make(chan string, 1)
+)
+
+// currentFrame copies the state the playfield is drawn from
+func currentFrame() runFrame {
+	frame := runFrame{
+		xPos:       snakePlayer.xPos,
+		yPos:       snakePlayer.yPos,
+		direction:  snakePlayer.direction,
+		path:       append([]pathPair(nil), snakePath...),
+		bodyLen:    len(snakePlayer.snakeBody),
+		nom:        currentNom,
+		nomActive:  nomActive,
+		nomGolden:  nomGolden,
+		score:      currScore,
+		speed:      runRamp.Shown,
+		frames:     runRamp.Clock.Frames(),
+		shrinkRing: shrinkRing,
+		delay:      (runRamp.Speed + 1) * centisPerSecond / simclock.FramesPerSecond,
+	}
+	for _, h := range hazards {
+		copied := *h
+		frame.hazards = append(frame.hazards, &copied)
+	}
+	return frame
+}
+
+// applyFrame puts a saved frame back into the game's state
+func applyFrame(frame runFrame) {
+	snakePlayer.xPos = frame.xPos
+	snakePlayer.yPos = frame.yPos
+	snakePlayer.direction = frame.direction
+	snakePath = frame.path
+	snakePlayer.snakeBody = nil
+	for segment := frame.bodyLen - 1; segment >= 0; segment-- {
+		snakePlayer.snakeBody = append(snakePlayer.snakeBody, snakeBody{segment: segment})
+	}
+	currentNom = frame.nom
+	nomActive = frame.nomActive
+	nomGolden = frame.nomGolden
+	hazards = frame.hazards
+	currScore = frame.score
+	runRamp.Shown = frame.speed
+	runRamp.Clock.SetFrames(frame.frames)
+	shrinkRing = frame.shrinkRing
+	placeBody()
+}
+
+// captureFrame keeps the frame that was just drawn, called once a move
+func captureFrame() {
+	frame := currentFrame()
+	if len(runFrames) > 0 {
+		last := runFrames[len(runFrames)-1]
+		frame.at = last.at + last.delay
+	}
+	runFrames = append(runFrames, frame)
+}
+
+// drawPlayfield draws a frame the way doGame does
+func drawPlayfield(screen *ebiten.Image) {
+	drawBoard(screen)
+	drawHUD(screen)
+	drawSnake(screen)
+	drawHazards(screen)
+	if nomActive {
+		drawNom(screen)
+	}
+}
+
+// exportGIF starts saving the end of the run, or all of it, as a GIF
+func exportGIF(whole bool) {
+	if exportJob != nil || len(runFrames) == 0 {
+		return
+	}
+	if !canWriteFiles {
+		gifStatus = tr("gif.unavailable")
+		return
+	}
+
+	frames := runFrames
+	scale := gifWholeScale
+	if whole && currentRun != nil && currentRun.Verifiable() {
+		// Play the whole run back from its replay, every move of it
+		if played, err := replayFrames(currentRun); err == nil {
+			frames = played
+		}
+	}
+	if !whole {
+		scale = gifClipScale
+		last := frames[len(frames)-1]
+		start := len(frames) - 1
+		for start > 0 && last.at-frames[start-1].at <= gifClipSeconds*centisPerSecond {
+			start--
+		}
+		frames = frames[start:]
+	}
+
+	base := fmt.Sprintf("go-snake-%s-%d-%s", replayMode(), currScore, time.Now().Format("20060102-150405"))
+	exportJob = newGIFJob(frames, scale, base+".gif")
+	gifStatus = tr("gif.saving")
+
+	// Save the replay too, so the whole run can be exported again later
+	if currentRun != nil && currentRun.Verifiable() {
+		if err := currentRun.Save(base + ".replay.json"); err != nil {
+			gifStatus = tr("gif.replay_error", err.Error())
+		}
+	}
+}
+
+func newGIFJob(frames []runFrame, scale float64, path string) *gifJob {
+	width, height := int(float64(ScreenWidth)*scale), int(float64(ScreenHeight)*scale)
+	return &gifJob{
+		frames: frames,
+		scale:  scale,
+		path:   path,
+		colors: map[color.RGBA]uint8{},
+		board:  ebiten.NewImage(ScreenWidth, ScreenHeight),
+		small:  ebiten.NewImage(width, height),
+	}
+}
+
+// step renders the next few frames. It has to run inside Draw, and puts the
+// game's state back the way it was when it's done.
+func (job *gifJob) step(count int) {
+	saved := currentFrame()
+	savedBody := snakePlayer.snakeBody
+	for ; count > 0 && job.next < len(job.frames); count-- {
+		frame := job.frames[job.next]
+		applyFrame(frame)
+		job.board.Fill(color.Black)
+		drawPlayfield(job.board)
+
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(job.scale, job.scale)
+		job.small.Clear()
+		job.small.DrawImage(job.board, op)
+		job.images = append(job.images, job.quantize(job.small))
+		job.delays = append(job.delays, frame.delay)
+		job.next++
+	}
+	applyFrame(saved)
+	snakePlayer.snakeBody = savedBody
+}
+
+func (job *gifJob) done() bool {
+	return job.next >= len(job.frames)
+}
+
+// quantize reads the image back and maps it onto the GIF palette. The game
+// only uses a handful of colors, so remembering each one's index makes this quick.
+func (job *gifJob) quantize(img *ebiten.Image) *image.Paletted {
+	bounds := img.Bounds()
+	pixels := make([]byte, 4*bounds.Dx()*bounds.Dy())
+	img.ReadPixels(pixels)
+
+	paletted := image.NewPaletted(bounds, palette.Plan9)
+	for idx := range paletted.Pix {
+		c := color.RGBA{pixels[idx*4], pixels[idx*4+1], pixels[idx*4+2], 0xff}
+		index, ok := job.colors[c]
+		if !ok {
+			index = uint8(color.Palette(palette.Plan9).Index(c))
+			job.colors[c] = index
+		}
+		paletted.Pix[idx] = index
+	}
+	return paletted
+}
+
+func (job *gifJob) write() error {
+	file, err := os.Create(job.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return gif.EncodeAll(file, &gif.GIF{Image: job.images, Delay: job.delays})
+}
+
+// doExport carries on with a GIF being saved, from the game over screen
+func doExport(screen *ebiten.Image) {
+	if exportJob == nil {
+		return
+	}
+	exportJob.step(gifFramesPerDraw)
+	if !exportJob.done() {
+		gifStatus = tr("gif.progress", exportJob.next*100/len(exportJob.frames))
+		return
+	}
+	job := exportJob
+	exportJob = nil
+	catalog := lang
+	go func() {
+		if err := job.write(); err != nil {
+			gifResults <- catalog.Text("gif.error", err.Error())
+			return
+		}
+		gifResults <- catalog.Text("saved", job.path)
+	}()
+}
+
+// pollExport picks up the result of the last GIF written
+func pollExport() {
+	select {
+	case status := <-gifResults:
+		gifStatus = status
+	default:
+	}
+}
+
+// replayFrames plays a saved run back through the rules, a frame per move
+func replayFrames(run *replay.Replay) ([]runFrame, error) {
+	if !run.Verifiable() {
+		return nil, fmt.Errorf("%s runs can't be played back, only runs on a difficulty", run.Mode)
+	}
+	arena := rules.NewArena(rules.DefaultWidth, rules.DefaultHeight, run.Seed, 1)
+	arena.SetCurve(run.Curve)
+	frames := []runFrame{arenaFrame(arena, 0)}
+	for move := 0; move < len(run.Moves) && !arena.Over(); move++ {
+		direction := run.Direction(move)
+		if direction == "" {
+			return nil, fmt.Errorf("move %d: bad direction", move)
+		}
+		at := frames[len(frames)-1].at + frames[len(frames)-1].delay
+		arena.SetDirection(0, direction)
+		arena.Step()
+		frames = append(frames, arenaFrame(arena, at))
+	}
+	return frames, nil
+}
+
+func arenaFrame(arena *rules.Arena, at int) runFrame {
+	snake := arena.Snakes[0]
+	frame := runFrame{
+		xPos:      snake.Head().X,
+		yPos:      snake.Head().Y,
+		direction: snake.Direction,
+		bodyLen:   len(snake.Body) - 1,
+		nom:       pathPair{arena.Apple.X, arena.Apple.Y, "", false},
+		nomActive: arena.InBounds(arena.Apple),
+		score:     snake.Score,
+		speed:     arena.Ramp.Shown,
+		frames:    at * simclock.FramesPerSecond / centisPerSecond,
+		delay:     arena.FramesPerMove() * centisPerSecond / simclock.FramesPerSecond,
+		at:        at,
+	}
+	// Like snakePath once a move is done, the path starts under the head
+	for idx, cell := range snake.Body {
+		vertical := snake.Direction == rules.Up || snake.Direction == rules.Down
+		if idx > 0 {
+			vertical = cell.X == snake.Body[idx-1].X
+		}
+		orientation := "horizontal"
+		if vertical {
+			orientation = "vertical"
+		}
+		frame.path = append(frame.path, pathPair{cell.X, cell.Y, orientation, false})
+	}
+	return frame
+}
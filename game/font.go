@@ -0,0 +1,109 @@
+package game
+
+import (
+	"image"
+	"io/fs"
+	"log"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/brantleyr/go-snake/assets"
+)
+
+// The text fonts are tried in order for each letter. Jungle Adventurer only
+// has Latin letters, M+ covers Japanese and the rest of what the languages need.
+var textFontFiles = []string{"fonts/JungleAdventurer.ttf", "fonts/mplus-1p-regular.ttf"}
+
+var textFonts []*opentype.Font
+
+func loadFonts() {
+	for _, file := range textFontFiles {
+		data, err := fs.ReadFile(assets.FS, file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		parsed, err := opentype.Parse(data)
+		if err != nil {
+			log.Fatal(err)
+		}
+		textFonts = append(textFonts, parsed)
+	}
+}
+
+// newTextFace is a face of the text fonts at size, falling back from one to
+// the next for letters the first doesn't have
+func newTextFace(size float64) font.Face {
+	face := &fallbackFace{picked: map[rune]int{}}
+	for _, parsed := range textFonts {
+		sized, err := opentype.NewFace(parsed, &opentype.FaceOptions{
+			Size:    size,
+			DPI:     dpi,
+			Hinting: font.HintingFull,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		face.faces = append(face.faces, sized)
+		face.fonts = append(face.fonts, parsed)
+	}
+	return face
+}
+
+// fallbackFace draws each letter with the first face that has it. Line
+// heights come from the first face, so layouts don't move between languages.
+type fallbackFace struct {
+	faces  []font.Face
+	fonts  []*opentype.Font
+	buf    sfnt.Buffer
+	picked map[rune]int // which face each letter comes from
+}
+
+func (f *fallbackFace) pick(r rune) font.Face {
+	idx, ok := f.picked[r]
+	if !ok {
+		// If nobody has it, the first face draws its missing letter box
+		idx = 0
+		for try, parsed := range f.fonts {
+			if glyph, err := parsed.GlyphIndex(&f.buf, r); err == nil && glyph != 0 {
+				idx = try
+				break
+			}
+		}
+		f.picked[r] = idx
+	}
+	return f.faces[idx]
+}
+
+func (f *fallbackFace) Close() error {
+	for _, face := range f.faces {
+		face.Close()
+	}
+	return nil
+}
+
+func (f *fallbackFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	return f.pick(r).Glyph(dot, r)
+}
+
+func (f *fallbackFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	return f.pick(r).GlyphBounds(r)
+}
+
+func (f *fallbackFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	return f.pick(r).GlyphAdvance(r)
+}
+
+// Kern only means something between two letters of the same font
+func (f *fallbackFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	if face := f.pick(r0); face == f.pick(r1) {
+		return face.Kern(r0, r1)
+	}
+	return 0
+}
+
+func (f *fallbackFace) Metrics() font.Metrics {
+	return f.faces[0].Metrics()
+}
@@ -9,31 +9,27 @@ import (
 	"io/fs"
 	"log"
 	"math"
-	"math/rand"
-	"os"
-	"strconv"
-	"time"
 
 	"golang.org/x/image/font"
-	"golang.org/x/image/font/opentype"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/audio"
 	"github.com/hajimehoshi/ebiten/v2/audio/mp3"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
-	"github.com/hajimehoshi/ebiten/v2/text"
+
+	"github.com/brantleyr/go-snake/assets"
+	"github.com/brantleyr/go-snake/layout"
+	"github.com/brantleyr/go-snake/simclock"
 )
 
 const (
-	DEBUG_MODE        = true
 	dpi               = 72
 	baseFontSize      = 36
 	titleFontSize     = 72
 	scoreFontSize     = 24
 	timerFontSize     = 24
 	GameTitle         = "Go Snake"
-	startGameText     = "Use arrow keys to guide Snake.\n    Press Enter to start."
 	drNickImageSrc    = "images/dr-nick.png"
 	schImageSrc       = "images/schneider.png"
 	rhImageSrc        = "images/red-hat.png"
@@ -42,6 +38,9 @@ const (
 	snakeLogoImageSrc = "images/snake-logo.png"
 	globBgImageSrc    = "images/green-bg.png"
 	appleImageSrc     = "images/apple.png"
+	hazardBlockSrc    = "images/hazard-block.png"
+	hazardBallSrc     = "images/hazard-ball.png"
+	mouseImageSrc     = "images/mouse.png"
 	greenGridImageSrc = "images/green-grid.png"
 	gridHeight        = 20
 	gridWidth         = 25
@@ -61,6 +60,7 @@ const (
 type pathPair struct {
 	xPos, yPos  int
 	orientation string
+	warped      bool // came out of a portal, so not next to the pair in front of it
 }
 
 type snakeBody struct {
@@ -76,6 +76,46 @@ type snake struct {
 	direction string // up, down, left, right
 }
 
+// titleMenu is built in init, its actions open the other screens
+var titleMenu *menu
+
+func buildTitleMenu() {
+	play := func(state string) func() {
+		return func() { showScreen(state) }
+	}
+	var multiplayer *menu
+	multiplayer = newMenu(
+		menuItem{label: tr("menu.host"), action: hostGame, enabled: netplayAvailable},
+		menuItem{label: tr("menu.join"), action: play("net_join"), enabled: netplayAvailable},
+		menuItem{label: tr("menu.back"), action: func() { titleMenu.close() }},
+	)
+
+	items := []menuItem{
+		{label: tr("menu.new_game"), action: play("game")},
+		{label: tr("menu.shrink"), action: play("game_shrink")},
+		{label: tr("menu.levels"), action: play("game_level")},
+		{label: tr("menu.practice"), action: play("game_practice")},
+		{label: tr("menu.difficulty"), action: play("difficulty")},
+		{label: tr("menu.daily"), action: enterDaily},
+		{label: tr("menu.leaderboard"), action: func() {
+			fetchLeaderboard()
+			showScreen("leaderboard")
+		}},
+		{label: tr("menu.multiplayer"), submenu: multiplayer},
+		{label: tr("menu.settings"), action: func() { pushScene(&settingsScene{}) }},
+	}
+	// There is nothing to exit to in a browser
+	if canQuit {
+		items = append(items, menuItem{label: tr("menu.exit"), action: requestQuit})
+	}
+	titleMenu = newMenu(items...)
+	titleMenu.visible = 9
+}
+
+func netplayAvailable() bool {
+	return canNetplay
+}
+
 var (
 	snakePlayer               snake
 	drNick                    *ebiten.Image
@@ -112,15 +152,14 @@ var (
 	snakeTailHorizontalRed    *ebiten.Image
 	snakeTailVerticalRed      *ebiten.Image
 	snakeDead                 *ebiten.Image
+	hazardBlockImage          *ebiten.Image
+	hazardBallImage           *ebiten.Image
+	mouseImage                *ebiten.Image
 	baseFont                  font.Face
 	titleFont                 font.Face
 	scoreFont                 font.Face
 	timerFont                 font.Face
-	GameStarted               = false
-	GamePaused                = false
-	GameOver                  = false
-	GameState                 = "title" // intro, title, game, exit
-	menuItem                  string
+	GameState                 = "title" // the screen or mode being played, set by the scene when it opens, see screens
 	ScreenWidth               = 1024
 	ScreenHeight              = 768
 	gridCellHeight            int
@@ -128,23 +167,17 @@ var (
 	snakePath                 []pathPair
 	nomActive                 = false
 	currentNom                pathPair
-	clockSpeed                = 20
-	clockSpeedHuman           = 1
 	currScore                 = 0
 	globBgRot                 = 0.75
 	zoomingBg                 = true
 	introOpacity              = 0.0
 	fadingOutIntro            = false
-	timeElapsed               = 0
-	timerDone                 = make(chan bool)
-	timerTicker               = time.NewTicker(1 * time.Second)
 	appleScale                = .1
 	zoomingApple              = true
 	emptyImage                = ebiten.NewImage(3, 3)
 	emptySubImage             = emptyImage.SubImage(image.Rect(1, 1, 2, 2)).(*ebiten.Image)
 	gameOverSnd               *audio.Player
 	gameOverFile              fs.File
-	GameJustEnded             = false
 	GameOverSndPlaying        = true
 	// scoreBoard         string  TODO: USE THIS
 	pieceColor          = "#00ff00"
@@ -154,6 +187,7 @@ var (
 	manualColorOverride = false
 	manualColor         = "green"
 	muted               = true
+	headWarped          = false
 )
 
 func setupInitialSnake() {
@@ -166,14 +200,14 @@ func setupInitialSnake() {
 
 	// Initial Path
 	snakePath = []pathPair{
-		{0, 2, "vertical"},
-		{0, 1, "vertical"},
-		{0, 0, "vertical"},
+		{0, 2, "vertical", false},
+		{0, 1, "vertical", false},
+		{0, 0, "vertical", false},
 	}
 }
 
 func openFile(path string) fs.File {
-	file, err := os.Open(path)
+	file, err := assets.FS.Open(path)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -195,212 +229,206 @@ func decodeMP3(ctx *audio.Context, src io.ReadSeeker) *audio.Player {
 func init() {
 	var err error
 
+	// Update steps the run, so it has to tick as often as the game clock counts
+	ebiten.SetTPS(simclock.FramesPerSecond)
+
 	// Fill the subimage
 	// Used for DrawLine
 	emptyImage.Fill(color.White)
 
 	// Load intro images
-	drNick, _, err = ebitenutil.NewImageFromFile(drNickImageSrc)
+	drNick, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, drNickImageSrc)
 	if err != nil {
 		log.Fatal(err)
 	}
-	schImage, _, err = ebitenutil.NewImageFromFile(schImageSrc)
+	schImage, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, schImageSrc)
 	if err != nil {
 		log.Fatal(err)
 	}
-	rhImage, _, err = ebitenutil.NewImageFromFile(rhImageSrc)
+	rhImage, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, rhImageSrc)
 	if err != nil {
 		log.Fatal(err)
 	}
-	ebImage, _, err = ebitenutil.NewImageFromFile(ebImageSrc)
+	ebImage, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, ebImageSrc)
 	if err != nil {
 		log.Fatal(err)
 	}
-	goImage, _, err = ebitenutil.NewImageFromFile(goImageSrc)
+	goImage, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, goImageSrc)
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	// Load global bg
-	globBg, _, err = ebitenutil.NewImageFromFile(globBgImageSrc)
+	globBg, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, globBgImageSrc)
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	// Load snake logo
-	snakeLogo, _, err = ebitenutil.NewImageFromFile(snakeLogoImageSrc)
+	snakeLogo, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, snakeLogoImageSrc)
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	// Load apple image
-	apple, _, err = ebitenutil.NewImageFromFile(appleImageSrc)
+	apple, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, appleImageSrc)
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	// Load green grid tile
-	greenGrid, _, err = ebitenutil.NewImageFromFile(greenGridImageSrc)
+	greenGrid, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, greenGridImageSrc)
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	// Load snake images
 	// Green
-	snakeHeadUpGreen, _, err = ebitenutil.NewImageFromFile("images/snake-head-up-green.png")
+	snakeHeadUpGreen, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, "images/snake-head-up-green.png")
 	if err != nil {
 		log.Fatal(err)
 	}
-	snakeHeadDownGreen, _, err = ebitenutil.NewImageFromFile("images/snake-head-down-green.png")
+	snakeHeadDownGreen, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, "images/snake-head-down-green.png")
 	if err != nil {
 		log.Fatal(err)
 	}
-	snakeHeadLeftGreen, _, err = ebitenutil.NewImageFromFile("images/snake-head-left-green.png")
+	snakeHeadLeftGreen, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, "images/snake-head-left-green.png")
 	if err != nil {
 		log.Fatal(err)
 	}
-	snakeHeadRightGreen, _, err = ebitenutil.NewImageFromFile("images/snake-head-right-green.png")
+	snakeHeadRightGreen, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, "images/snake-head-right-green.png")
 	if err != nil {
 		log.Fatal(err)
 	}
-	snakeBodyVerticalGreen, _, err = ebitenutil.NewImageFromFile("images/snake-body-vertical-green.png")
+	snakeBodyVerticalGreen, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, "images/snake-body-vertical-green.png")
 	if err != nil {
 		log.Fatal(err)
 	}
-	snakeBodyHorizontalGreen, _, err = ebitenutil.NewImageFromFile("images/snake-body-horizontal-green.png")
+	snakeBodyHorizontalGreen, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, "images/snake-body-horizontal-green.png")
 	if err != nil {
 		log.Fatal(err)
 	}
-	snakeTailHorizontalGreen, _, err = ebitenutil.NewImageFromFile("images/snake-tail-horizontal-green.png")
+	snakeTailHorizontalGreen, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, "images/snake-tail-horizontal-green.png")
 	if err != nil {
 		log.Fatal(err)
 	}
-	snakeTailVerticalGreen, _, err = ebitenutil.NewImageFromFile("images/snake-tail-vertical-green.png")
+	snakeTailVerticalGreen, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, "images/snake-tail-vertical-green.png")
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	//Orange
-	snakeHeadUpOrange, _, err = ebitenutil.NewImageFromFile("images/snake-head-up-orange.png")
+	snakeHeadUpOrange, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, "images/snake-head-up-orange.png")
 	if err != nil {
 		log.Fatal(err)
 	}
-	snakeHeadDownOrange, _, err = ebitenutil.NewImageFromFile("images/snake-head-down-orange.png")
+	snakeHeadDownOrange, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, "images/snake-head-down-orange.png")
 	if err != nil {
 		log.Fatal(err)
 	}
-	snakeHeadLeftOrange, _, err = ebitenutil.NewImageFromFile("images/snake-head-left-orange.png")
+	snakeHeadLeftOrange, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, "images/snake-head-left-orange.png")
 	if err != nil {
 		log.Fatal(err)
 	}
-	snakeHeadRightOrange, _, err = ebitenutil.NewImageFromFile("images/snake-head-right-orange.png")
+	snakeHeadRightOrange, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, "images/snake-head-right-orange.png")
 	if err != nil {
 		log.Fatal(err)
 	}
-	snakeBodyVerticalOrange, _, err = ebitenutil.NewImageFromFile("images/snake-body-vertical-orange.png")
+	snakeBodyVerticalOrange, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, "images/snake-body-vertical-orange.png")
 	if err != nil {
 		log.Fatal(err)
 	}
-	snakeBodyHorizontalOrange, _, err = ebitenutil.NewImageFromFile("images/snake-body-horizontal-orange.png")
+	snakeBodyHorizontalOrange, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, "images/snake-body-horizontal-orange.png")
 	if err != nil {
 		log.Fatal(err)
 	}
-	snakeTailHorizontalOrange, _, err = ebitenutil.NewImageFromFile("images/snake-tail-horizontal-orange.png")
+	snakeTailHorizontalOrange, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, "images/snake-tail-horizontal-orange.png")
 	if err != nil {
 		log.Fatal(err)
 	}
-	snakeTailVerticalOrange, _, err = ebitenutil.NewImageFromFile("images/snake-tail-vertical-orange.png")
+	snakeTailVerticalOrange, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, "images/snake-tail-vertical-orange.png")
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	//Red
-	snakeHeadUpRed, _, err = ebitenutil.NewImageFromFile("images/snake-head-up-red.png")
+	snakeHeadUpRed, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, "images/snake-head-up-red.png")
 	if err != nil {
 		log.Fatal(err)
 	}
-	snakeHeadDownRed, _, err = ebitenutil.NewImageFromFile("images/snake-head-down-red.png")
+	snakeHeadDownRed, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, "images/snake-head-down-red.png")
 	if err != nil {
 		log.Fatal(err)
 	}
-	snakeHeadLeftRed, _, err = ebitenutil.NewImageFromFile("images/snake-head-left-red.png")
+	snakeHeadLeftRed, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, "images/snake-head-left-red.png")
 	if err != nil {
 		log.Fatal(err)
 	}
-	snakeHeadRightRed, _, err = ebitenutil.NewImageFromFile("images/snake-head-right-red.png")
+	snakeHeadRightRed, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, "images/snake-head-right-red.png")
 	if err != nil {
 		log.Fatal(err)
 	}
-	snakeBodyVerticalRed, _, err = ebitenutil.NewImageFromFile("images/snake-body-vertical-red.png")
+	snakeBodyVerticalRed, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, "images/snake-body-vertical-red.png")
 	if err != nil {
 		log.Fatal(err)
 	}
-	snakeBodyHorizontalRed, _, err = ebitenutil.NewImageFromFile("images/snake-body-horizontal-red.png")
+	snakeBodyHorizontalRed, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, "images/snake-body-horizontal-red.png")
 	if err != nil {
 		log.Fatal(err)
 	}
-	snakeTailHorizontalRed, _, err = ebitenutil.NewImageFromFile("images/snake-tail-horizontal-red.png")
+	snakeTailHorizontalRed, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, "images/snake-tail-horizontal-red.png")
 	if err != nil {
 		log.Fatal(err)
 	}
-	snakeTailVerticalRed, _, err = ebitenutil.NewImageFromFile("images/snake-tail-vertical-red.png")
+	snakeTailVerticalRed, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, "images/snake-tail-vertical-red.png")
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	// Dead snake - Game Over
-	snakeDead, _, err = ebitenutil.NewImageFromFile("images/snake-dead.png")
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	// Make snake
-	setupInitialSnake()
-
-	// Load basic font
-	externalFont, err := os.ReadFile("fonts/JungleAdventurer.ttf")
-	if err != nil {
-		log.Fatal(err)
-	}
-	tt, err := opentype.Parse(externalFont)
+	snakeDead, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, "images/snake-dead.png")
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	baseFont, err = opentype.NewFace(tt, &opentype.FaceOptions{
-		Size:    baseFontSize,
-		DPI:     dpi,
-		Hinting: font.HintingFull,
-	})
+	// Hazards
+	hazardBlockImage, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, hazardBlockSrc)
 	if err != nil {
 		log.Fatal(err)
 	}
-	titleFont, err = opentype.NewFace(tt, &opentype.FaceOptions{
-		Size:    titleFontSize,
-		DPI:     dpi,
-		Hinting: font.HintingFull,
-	})
+	hazardBallImage, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, hazardBallSrc)
 	if err != nil {
 		log.Fatal(err)
 	}
-	scoreFont, err = opentype.NewFace(tt, &opentype.FaceOptions{
-		Size:    scoreFontSize,
-		DPI:     dpi,
-		Hinting: font.HintingFull,
-	})
+	mouseImage, _, err = ebitenutil.NewImageFromFileSystem(assets.FS, mouseImageSrc)
 	if err != nil {
 		log.Fatal(err)
 	}
-	timerFont, err = opentype.NewFace(tt, &opentype.FaceOptions{
-		Size:    timerFontSize,
-		DPI:     dpi,
-		Hinting: font.HintingFull,
-	})
-	if err != nil {
-		log.Fatal(err)
+
+	// Make snake
+	setupInitialSnake()
+
+	// Load level layouts
+	loadLevels()
+	if len(levels) > 0 {
+		currentLevel = levels[levelIndex]
 	}
 
-	menuItem = "new_game"
+	// Past daily challenge results
+	loadDailyHistory()
+	loadHighScores()
+	loadLanguages()
+	loadDifficulties()
+	loadSettings()
+
+	// Load fonts
+	loadFonts()
+	baseFont = newTextFace(baseFontSize)
+	titleFont = newTextFace(titleFontSize)
+	scoreFont = newTextFace(scoreFontSize)
+	timerFont = newTextFace(timerFontSize)
+
+	buildTitleMenu()
 
 	// Initialize sounds
 	ctx := audio.NewContext(sampleRate)
@@ -421,122 +449,202 @@ func doColorOverride() {
 	} else if manualColor == "red" {
 		manualColor = "green"
 	}
+	saveSettings()
 }
 
 func (g *Game) Update() error {
-	// Handle "intro" game state key events
-	if GameState == "intro" {
-		if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
-			fadingOutIntro = true
-		}
+	if quitRequested {
+		quitRequested = false
+		return quitGame()
+	}
 
-		// Handle "title" game state key events
-	} else if GameState == "title" {
-		if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
-			if menuItem == "new_game" {
-				GameState = "game"
-			} else if menuItem == "new_game_hard" {
-				GameState = "game_hard"
-			} else if menuItem == "exit" {
-				// Loop to the down
-				GameState = "exit"
-			}
-		}
-		if inpututil.IsKeyJustPressed(ebiten.KeyArrowDown) ||
-			inpututil.IsKeyJustPressed(ebiten.KeyS) {
-			if menuItem == "new_game" {
-				// They just moved down
-				menuItem = "new_game_hard"
-			} else if menuItem == "new_game_hard" {
-				menuItem = "exit"
-			} else if menuItem == "exit" {
-				// Loop to the top
-				menuItem = "new_game"
-			}
-		} else if inpututil.IsKeyJustPressed(ebiten.KeyArrowUp) ||
-			inpututil.IsKeyJustPressed(ebiten.KeyW) {
-			if menuItem == "new_game" {
-				// They just moved up
-				menuItem = "exit"
-			} else if menuItem == "new_game_hard" {
-				menuItem = "new_game"
-			} else if menuItem == "exit" {
-				menuItem = "new_game_hard"
-			}
-		}
+	// Answers from the leaderboard server and the GIF writer
+	pollLeaderboard()
+	pollExport()
 
-		// Handle "game" game state key events
-	} else if GameState == "game" || GameState == "game_hard" {
-		if inpututil.IsKeyJustPressed(ebiten.KeyC) {
-			doColorOverride()
-		}
-		if GameStarted && !GameOver {
-			if !GamePaused {
-				if snakePlayer.direction != "up" && snakePlayer.direction != "down" {
-					if inpututil.IsKeyJustPressed(ebiten.KeyUp) ||
-						inpututil.IsKeyJustPressed(ebiten.KeyW) {
-						snakePlayer.direction = "up"
-					}
-					if inpututil.IsKeyJustPressed(ebiten.KeyDown) ||
-						inpututil.IsKeyJustPressed(ebiten.KeyS) {
-						snakePlayer.direction = "down"
-					}
-				}
-				if snakePlayer.direction != "left" && snakePlayer.direction != "right" {
-					if inpututil.IsKeyJustPressed(ebiten.KeyLeft) ||
-						inpututil.IsKeyJustPressed(ebiten.KeyA) {
-						snakePlayer.direction = "left"
-					}
-					if inpututil.IsKeyJustPressed(ebiten.KeyRight) ||
-						inpututil.IsKeyJustPressed(ebiten.KeyD) {
-						snakePlayer.direction = "right"
-					}
-				}
-				if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
-					GamePaused = true
-				}
-			} else {
-				if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
-					GamePaused = false
-				} else if inpututil.IsKeyJustPressed(ebiten.KeyQ) {
-					GameState = "exit"
-				}
-			}
-		} else {
-			if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
-				GameStarted = true
-			}
+	// Screenshots work anywhere, they're taken once the frame is drawn
+	if inpututil.IsKeyJustPressed(ebiten.KeyF12) {
+		screenshotWanted = true
+	}
+
+	// The developer console takes the keyboard while it's open
+	if handleDebugKeys() {
+		return nil
+	}
+
+	if err := updateScenes(g); err != nil {
+		return err
+	}
+	// The run moves on under the pause and game over menus too, they
+	// hold it still themselves
+	if sceneOpen(func(scene Scene) bool { _, ok := scene.(*gameScene); return ok }) {
+		stepGame(g)
+	}
+	return nil
+}
+
+// introScene shows the logos, fading them in and then out to the title
+type introScene struct {
+	hold int // updates left at full opacity
+}
+
+func (s *introScene) Enter() {
+	introOpacity = 0
+	fadingOutIntro = false
+	s.hold = simclock.FramesPerSecond
+}
+
+func (s *introScene) Exit() {}
+
+func (s *introScene) Update(g *Game) error {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		fadingOutIntro = true
+	}
+
+	// Increment opacity (fade in)
+	if fadingOutIntro {
+		introOpacity -= .01
+		if introOpacity <= 0 {
+			showScreen("title")
 		}
-		if GameOver {
-			if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
-				GameStarted = true
-				GameOver = false
-				GameOverSndPlaying = false
-				GameJustEnded = false
-				currScore = 0
-				clockSpeed = 20
-				clockSpeedHuman = 1
-
-				timeElapsed = 1 // it starts slower than the first timer for some reason
-				timerTicker.Reset(1 * time.Second)
-
-				setupInitialSnake()
-			} else if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
-				GameState = "exit"
-			} else if inpututil.IsKeyJustPressed(ebiten.KeyM) {
-				if GameState == "game" {
-					GameState = "game_hard"
-				} else {
-					// Hard -> Normal
-					GameState = "game"
-				}
-			}
+	} else if introOpacity < 1 {
+		introOpacity += .01
+	} else {
+		introOpacity = 1
+		s.hold--
+		if s.hold <= 0 {
+			showScreen("title")
 		}
 	}
+	return nil
+}
+
+func (s *introScene) Draw(g *Game, screen *ebiten.Image) {
+	doIntro(g, screen)
+}
+
+type titleScene struct{}
+
+func (s *titleScene) Enter() {
+	GameState = "title"
+}
+
+func (s *titleScene) Exit() {}
+
+func (s *titleScene) Update(g *Game) error {
+	titleMenu.update()
+	return nil
+}
+
+func (s *titleScene) Draw(g *Game, screen *ebiten.Image) {
+	drawTitle(screen)
+	// Settings open over the title in the menu's place
+	if sceneOnTop(s) {
+		doTitle(g, screen)
+	}
+}
+
+// gameScene plays one of the modes, from the start text to the crash.
+// Pausing and the game over screen are scenes of their own on top of it.
+type gameScene struct {
+	mode string
+}
+
+func (s *gameScene) Enter() {
+	GameState = s.mode
+	resetHazards()
+}
 
+func (s *gameScene) Exit() {}
+
+func (s *gameScene) Update(g *Game) error {
+	if inpututil.IsKeyJustPressed(ebiten.KeyC) {
+		doColorOverride()
+	}
+	if GameState == "game_practice" {
+		handlePracticeKeys()
+	}
+	heading := snakeHeading
+	if heading == "" {
+		heading = snakePlayer.direction
+	}
+	if next := steerPressed(snakePlayer.direction, heading); next != "" {
+		snakePlayer.direction = next
+	}
+	if pausePressed() {
+		openRunMenu()
+	}
+	return nil
+}
+
+func (s *gameScene) Draw(g *Game, screen *ebiten.Image) {
+	doGame(g, screen)
+}
+
+// startScene holds a new game behind the start text until the player's ready
+type startScene struct{}
+
+func (s *startScene) Enter() {}
+
+func (s *startScene) Exit() {}
+
+func (s *startScene) Update(g *Game) error {
+	if inpututil.IsKeyJustPressed(ebiten.KeyC) {
+		doColorOverride()
+	}
+	if menuChoosePressed() {
+		popScene()
+		restartRun()
+	}
 	return nil
 }
 
+func (s *startScene) Draw(g *Game, screen *ebiten.Image) {
+	drawBlackOverlay(screen)
+	startText := tr("start.keys", controlsHelp())
+	if GameState == "game_practice" {
+		startText = tr("start.keys_practice", controlsHelp())
+	}
+	drawText(screen, startText, baseFont, screenArea(), layout.Center, color.White)
+}
+
+// Where the run is comes from the scenes open over the game: it waits under
+// the start text, pauses under the pause menu and is over under the game
+// over screen
+func gamePaused() bool {
+	return sceneOpen(func(scene Scene) bool { _, ok := scene.(*pauseScene); return ok })
+}
+
+func gameOver() bool {
+	return sceneOpen(func(scene Scene) bool { _, ok := scene.(*gameOverScene); return ok })
+}
+
+// gameStarted is whether the snake is out on the board, paused or not
+func gameStarted() bool {
+	waiting := sceneOpen(func(scene Scene) bool { _, ok := scene.(*startScene); return ok })
+	playing := sceneOpen(func(scene Scene) bool { _, ok := scene.(*gameScene); return ok })
+	return playing && !waiting && !gameOver()
+}
+
+// snakeCrashed ends the game, or just costs a few pieces in practice mode
+func snakeCrashed() {
+	if godMode {
+		wrapHead()
+		return
+	}
+	if GameState == "game_practice" {
+		practiceHit()
+		return
+	}
+	if gameOver() {
+		// Already over, the head stays where it hit until the next run
+		return
+	}
+	recordDailyResult()
+	finishRun()
+	pushScene(&gameOverScene{})
+}
+
 func ParseHexColor(s string) (c color.RGBA) {
 	c.A = 0xff
 	switch len(s) {
@@ -605,23 +713,6 @@ func doIntro(g *Game, screen *ebiten.Image) {
 	goOp.ColorM.Scale(1, 1, 1, introOpacity)
 	screen.DrawImage(goImage, goOp)
 
-	// Increment opacity (fade in)
-	if fadingOutIntro {
-		introOpacity -= .01
-		if introOpacity <= 0 {
-			GameState = "title"
-			fadingOutIntro = false
-		}
-	} else {
-		introOpacity += .01
-		if introOpacity >= 1 {
-			introOpacity = 1
-
-			time.Sleep(1 * time.Second)
-			GameState = "title"
-		}
-	}
-
 }
 
 func drawBg(screen *ebiten.Image) {
@@ -629,6 +720,9 @@ func drawBg(screen *ebiten.Image) {
 	globBgOp.GeoM.Scale(globBgRot, globBgRot)
 	screen.DrawImage(globBg, globBgOp)
 
+	if reducedMotion {
+		return
+	}
 	if zoomingBg {
 		globBgRot += .0001
 	} else {
@@ -652,33 +746,25 @@ func drawTitle(screen *ebiten.Image) {
 	snake.GeoM.Scale(.50, .50)
 	snake.GeoM.Translate(float64((ScreenWidth/2))-(float64(ScreenWidth)*0.17), float64(ScreenHeight)*0.06125)
 	screen.DrawImage(snakeLogo, snake)
-
-	// Handle Menu
-	if menuItem == "new_game" {
-		text.Draw(screen, "> New Game", titleFont, (ScreenWidth/3)-30, (ScreenHeight/3)+190, color.White)
-		text.Draw(screen, "New Game (Hard)", titleFont, (ScreenWidth/3)+20, (ScreenHeight/3)+270, ParseHexColor("#8c8c8c"))
-		text.Draw(screen, "Exit", titleFont, (ScreenWidth/3)+20, (ScreenHeight/3)+350, ParseHexColor("#8c8c8c"))
-	} else if menuItem == "new_game_hard" {
-		text.Draw(screen, "New Game", titleFont, (ScreenWidth/3)+20, (ScreenHeight/3)+190, ParseHexColor("#8c8c8c"))
-		text.Draw(screen, "> New Game (Hard)", titleFont, (ScreenWidth/3)-30, (ScreenHeight/3)+270, color.White)
-		text.Draw(screen, "Exit", titleFont, (ScreenWidth/3)+20, (ScreenHeight/3)+350, ParseHexColor("#8c8c8c"))
-	} else if menuItem == "exit" {
-		text.Draw(screen, "New Game", titleFont, (ScreenWidth/3)+20, (ScreenHeight/3)+190, ParseHexColor("#8c8c8c"))
-		text.Draw(screen, "New Game (Hard)", titleFont, (ScreenWidth/3)+20, (ScreenHeight/3)+270, ParseHexColor("#8c8c8c"))
-		text.Draw(screen, "> Exit", titleFont, (ScreenWidth/3)-30, (ScreenHeight/3)+350, color.White)
-	}
 }
 
 func doTitle(g *Game, screen *ebiten.Image) {
-	// TODO: Add some sort of way to detect the center of the screen
-	drawTitle(screen)
-
+	// Handle Menu
+	titleMenu.draw(screen, baseFont, image.Rect(0, (ScreenHeight/3)+58, ScreenWidth, ScreenHeight), 38)
 }
 
 func getGridCellColor(ix int, iy int) color.Color {
 
 	var theColor color.Color
 
+	// High contrast is a plain checkerboard with nothing showing through
+	if highContrast {
+		if (ix+iy)%2 == 0 {
+			return ParseHexColor("#1e1e1e")
+		}
+		return color.Black
+	}
+
 	if ix%2 == 0 {
 		if iy%2 == 0 {
 			theColor = ParseHexColorAlpha(gridSolidColor, gridCellOpacity)
@@ -712,17 +798,23 @@ func DrawLine(dst *ebiten.Image, x1, y1, x2, y2, width float64, clr color.Color)
 func buildGrid(screen *ebiten.Image) {
 
 	// Draw BG
-	drawBg(screen)
+	borderColor := ParseHexColor(gridBorderColor)
+	if highContrast {
+		screen.Fill(color.Black)
+		borderColor = ParseHexColor("#ffffff")
+	} else {
+		drawBg(screen)
+	}
 
 	// Draw Grid Border
 	// Top
-	DrawLine(screen, float64(borderLeft-gridBorderSize), float64(borderTop-gridBorderSize), float64(ScreenWidth-borderRight), float64(borderTop-gridBorderSize), gridBorderSize, ParseHexColor(gridBorderColor))
+	DrawLine(screen, float64(borderLeft-gridBorderSize), float64(borderTop-gridBorderSize), float64(ScreenWidth-borderRight), float64(borderTop-gridBorderSize), gridBorderSize, borderColor)
 	// Bottom
-	DrawLine(screen, float64(borderLeft-gridBorderSize), float64(ScreenHeight-borderBottom)-(float64(gridBorderSize)*3), float64(ScreenWidth-borderRight), float64(ScreenHeight-borderBottom)-(float64(gridBorderSize)*3), gridBorderSize, ParseHexColor(gridBorderColor))
+	DrawLine(screen, float64(borderLeft-gridBorderSize), float64(ScreenHeight-borderBottom)-(float64(gridBorderSize)*3), float64(ScreenWidth-borderRight), float64(ScreenHeight-borderBottom)-(float64(gridBorderSize)*3), gridBorderSize, borderColor)
 	// Left
-	DrawLine(screen, float64(borderLeft), float64(borderTop), float64(borderLeft), float64(ScreenHeight-borderBottom)-(float64(gridBorderSize)*2.5), gridBorderSize, ParseHexColor(gridBorderColor))
+	DrawLine(screen, float64(borderLeft), float64(borderTop), float64(borderLeft), float64(ScreenHeight-borderBottom)-(float64(gridBorderSize)*2.5), gridBorderSize, borderColor)
 	// Right
-	DrawLine(screen, float64(ScreenWidth-borderRight), float64(borderTop), float64(ScreenWidth-borderRight), float64(ScreenHeight-borderBottom)-(float64(gridBorderSize)*2.5), gridBorderSize, ParseHexColor(gridBorderColor))
+	DrawLine(screen, float64(ScreenWidth-borderRight), float64(borderTop), float64(ScreenWidth-borderRight), float64(ScreenHeight-borderBottom)-(float64(gridBorderSize)*2.5), gridBorderSize, borderColor)
 
 	// Calculate Grid width using borders
 	gridCellWidth = (ScreenWidth - borderLeft - borderRight) / gridWidth
@@ -752,12 +844,32 @@ func drawGridPiece(screen *ebiten.Image, ix int, iy int, theColor color.Color, s
 	if shapeType == "triangle" {
 		//TODO: For tail, for now its a smaller cicle
 	}
+	if shapeType == "hazard-block" || shapeType == "hazard-ball" || shapeType == "hazard-mouse" {
+		hazardImage := hazardBlockImage
+		if shapeType == "hazard-ball" {
+			hazardImage = hazardBallImage
+		} else if shapeType == "hazard-mouse" {
+			hazardImage = mouseImage
+		}
+		h := &ebiten.DrawImageOptions{}
+		h.GeoM.Scale(float64(gridCellWidth)/float64(hazardImage.Bounds().Dx()), float64(gridCellHeight)/float64(hazardImage.Bounds().Dy()))
+		h.GeoM.Translate(float64(ix*gridCellWidth)+float64(borderLeft), float64(iy*gridCellHeight)+float64(borderTop))
+		screen.DrawImage(hazardImage, h)
+	}
 	if shapeType == "apple" {
 		a := &ebiten.DrawImageOptions{}
 		a.GeoM.Scale(appleScale, appleScale)
 		a.GeoM.Translate(float64(ix*gridCellWidth)+5+float64(borderLeft), float64(iy*gridCellHeight)+2+float64(borderTop))
 		screen.DrawImage(apple, a)
 	}
+	if shapeType == "apple-golden" {
+		a := &ebiten.DrawImageOptions{}
+		a.GeoM.Scale(appleScale, appleScale)
+		a.GeoM.Translate(float64(ix*gridCellWidth)+5+float64(borderLeft), float64(iy*gridCellHeight)+2+float64(borderTop))
+		a.ColorM.Scale(1.2, .9, .1, 1)
+		a.ColorM.Translate(.25, .2, 0, 0)
+		screen.DrawImage(apple, a)
+	}
 	// Green snake head
 	if shapeType == "head-up-green" {
 		s := &ebiten.DrawImageOptions{}
@@ -980,113 +1092,86 @@ func drawGridPiece(screen *ebiten.Image, ix int, iy int, theColor color.Color, s
 
 }
 
-func doNoms(g *Game, screen *ebiten.Image) {
-
-	// Only generate a new nom if there isn't currently one
-	if !nomActive {
-		notValidNom := true
-		var randX, randY int
-
-		// Generate random x,y pairs until it is not found in the existing snake path
-		for notValidNom {
-			rand.Seed(time.Now().UnixNano())
-			randX = rand.Intn(gridWidth - 1)
-			randY = rand.Intn(gridHeight - 1)
-			notValidNom = false
-			for _, pathPair := range snakePath {
-				if pathPair.xPos == randX && pathPair.yPos == randY {
-					notValidNom = true
-					break
-				}
+// spawnNom puts a new apple somewhere the snake isn't
+func spawnNom() {
+	notValidNom := true
+	var randX, randY int
+
+	// Generate random x,y pairs until it is not found in the existing snake path or under the head
+	for notValidNom {
+		randX = gameRand.Intn(gridWidth - 1)
+		randY = gameRand.Intn(gridHeight - 1)
+		notValidNom = isBlockedCell(randX, randY) || (snakePlayer.xPos == randX && snakePlayer.yPos == randY)
+		for _, pathPair := range snakePath {
+			if pathPair.xPos == randX && pathPair.yPos == randY {
+				notValidNom = true
+				break
 			}
 		}
-		// Set the new nom and draw it on the screen
-		nomActive = true
-		currentNom = pathPair{randX, randY, ""}
-		drawGridPiece(screen, randX, randY, ParseHexColor(nomColor), "apple", 0)
+	}
+	nomActive = true
+	currentNom = pathPair{randX, randY, "", false}
+	nomGolden = goldenApples && gameRand.Intn(goldenAppleOdds) == 0
+}
 
-		// They just ate one, they potentially speed up!
-		if currScore >= 10 {
-			if currScore%10 == 0 {
-				if GameState == "game_hard" {
-					// Give the user a couple seconds to react after eating fruit
-					go func() {
-						time.Sleep(2 * time.Second)
-						clockSpeed -= 4
-						clockSpeedHuman += 2
-					}()
-				} else {
-					// Give the user a couple seconds to react after eating fruit
-					go func() {
-						time.Sleep(2 * time.Second)
-						clockSpeed -= 2
-						clockSpeedHuman += 1
-					}()
-				}
-			}
-			if clockSpeed <= 5 {
-				if GameState == "game_hard" {
-					clockSpeed = 4
-				} else {
-					clockSpeed = 5 // set a base so the game doesnt get ridiculously fast
-				}
-			}
-		}
+// doNoms eats the apple if the head is on it
+func doNoms() {
+	// Only generate a new nom if there isn't currently one
+	if !nomActive {
+		spawnNom()
 	}
 
-	// If theres already a nom, check to see if it intersects with the snake head or draw it
-	if nomActive {
-		if snakePlayer.xPos == currentNom.xPos && snakePlayer.yPos == currentNom.yPos {
-			nomActive = false
+	if snakePlayer.xPos == currentNom.xPos && snakePlayer.yPos == currentNom.yPos {
+		nomActive = false
 
-			// Add new snake piece
-			newSnakeBodyPiece := snakeBody{snakePlayer.xPos, snakePlayer.yPos, len(snakePlayer.snakeBody)}
-			snakePlayer.snakeBody = append([]snakeBody{newSnakeBodyPiece}, snakePlayer.snakeBody...)
+		// Add new snake piece
+		newSnakeBodyPiece := snakeBody{snakePlayer.xPos, snakePlayer.yPos, len(snakePlayer.snakeBody)}
+		snakePlayer.snakeBody = append([]snakeBody{newSnakeBodyPiece}, snakePlayer.snakeBody...)
 
-			// Increment score
-			currScore += 1
+		// Increment score
+		currScore += nomScore()
 
-		} else {
-			drawGridPiece(screen, currentNom.xPos, currentNom.yPos, ParseHexColor(nomColor), "apple", 0)
-		}
+		// They just ate one, they potentially speed up!
+		scheduleSpeedUp()
 	}
 }
 
-func showScore(screen *ebiten.Image) {
+func drawNom(screen *ebiten.Image) {
+	drawGridPiece(screen, currentNom.xPos, currentNom.yPos, ParseHexColor(nomColor), nomShape(), 0)
+}
+
+func nomShape() string {
+	if nomGolden {
+		return "apple-golden"
+	}
+	return "apple"
+}
 
-	diam := (float64(gridCellWidth/3) + float64(gridCellHeight/3))
-	radius := diam / 2
+// showScore draws the score in the middle of the area, with the apple just
+// to its left pulsing around its own middle
+func showScore(screen *ebiten.Image, area image.Rectangle) {
+	drawn := drawText(screen, num(currScore), scoreFont, area, layout.Center, color.White)
 
 	// Draw the apple
+	appleSize := apple.Bounds().Size()
 	a := &ebiten.DrawImageOptions{}
+	a.GeoM.Translate(-float64(appleSize.X)/2, -float64(appleSize.Y)/2)
 	a.GeoM.Scale(appleScale, appleScale)
-	a.GeoM.Translate(float64(ScreenWidth/2)+105+float64(borderLeft), float64(borderTop/2)-18)
+	// Half the apple at its biggest away from the score, see doAppleScale
+	a.GeoM.Translate(float64(drawn.Min.X-textPadding)-(float64(appleSize.X)*.05), float64(area.Min.Y+area.Max.Y)/2)
 	screen.DrawImage(apple, a)
-
-	// Score
-	text.Draw(screen, strconv.Itoa(currScore), scoreFont, (ScreenWidth/2)+int(radius)+140, (borderTop/2)+(int(radius)/2)+3, color.White)
-
 }
 
 // TODO: Make this do a thing
 // func doScoreboard() {
 // }
 
-func doTimer() {
-	go func() {
-		for {
-			select {
-			case <-timerDone:
-				return
-			case <-timerTicker.C:
-				timeElapsed += 1
-			}
-		}
-	}()
-}
-
 func doAppleScale() {
-	if GameStarted && !GamePaused {
+	if reducedMotion {
+		appleScale = .1
+		return
+	}
+	if gameStarted() && !gamePaused() {
 		if zoomingApple {
 			appleScale += .0005
 		} else {
@@ -1103,7 +1188,12 @@ func doAppleScale() {
 }
 
 func doBodyFactor() {
-	if !GamePaused {
+	if reducedMotion {
+		xBodyFactor = .5
+		yBodyFactor = .5
+		return
+	}
+	if !gamePaused() {
 		if zoomingBody {
 			xBodyFactor += .005
 			yBodyFactor += .005
@@ -1127,45 +1217,140 @@ func doBodyFactor() {
 	}
 }
 
+// modeLabel names the mode being played for the HUD
+func modeLabel() string {
+	if GameState == "game_shrink" {
+		return tr("mode.shrink")
+	} else if GameState == "game_daily" {
+		return tr("mode.daily", dailyToday.date)
+	} else if GameState == "game_practice" {
+		return tr("mode.practice")
+	} else if GameState == "game_level" && currentLevel != nil {
+		return currentLevel.name
+	}
+	return tr("mode.normal", difficultyName(activeDifficulty()))
+}
+
 func drawBlackOverlay(screen *ebiten.Image) {
 	ebitenutil.DrawRect(screen, 0, 0, float64(ScreenWidth), float64(ScreenHeight), ParseHexColorAlpha("#000000", 0x88))
 }
 
-func drawSnakeDead(screen *ebiten.Image) {
+// drawSnakeDead draws the dead snake at half size, sat on the bottom of area
+func drawSnakeDead(screen *ebiten.Image, area image.Rectangle) {
+	size := snakeDead.Bounds().Size().Div(2)
+	at := layout.Align(size, area, layout.Bottom, textPadding).Min
 	s := &ebiten.DrawImageOptions{}
 	s.GeoM.Scale(.5, .5)
-	s.GeoM.Translate(float64(ScreenWidth/2)-float64(100), float64(ScreenHeight/2)-float64(250))
+	s.GeoM.Translate(float64(at.X), float64(at.Y))
 	screen.DrawImage(snakeDead, s)
 }
 
-func doGame(g *Game, screen *ebiten.Image) {
+// drawBoard draws the grid and whatever walls the mode has
+func drawBoard(screen *ebiten.Image) {
 	// Draw background
 	buildGrid(screen)
 
+	// Shrinking arena walls
+	if GameState == "game_shrink" {
+		drawShrinkWalls(screen)
+	}
+
+	// Level walls and portals
+	if activeLevel() != nil {
+		drawLevelWalls(screen)
+		drawPortals(screen)
+	}
+}
+
+// drawHUD draws the score, mode, time and speed along the top
+func drawHUD(screen *ebiten.Image) {
+	// Mode, time, score and speed across the bar
+	columns := layout.Columns(hudArea(), 4)
+	drawText(screen, modeLabel(), timerFont, columns[0], layout.Left, ParseHexColor("#749e35"))
+	drawText(screen, tr("hud.seconds", decimal(float64(runRamp.Clock.Millis())/1000, 1)), timerFont, columns[1], layout.Left, color.White)
+	showScore(screen, columns[2])
+	drawText(screen, tr("hud.speed", num(runRamp.Shown)), timerFont, columns[3], layout.Right, color.White)
+}
+
+// placeBody moves each body piece to its place on the path
+func placeBody() {
+	for idx, snakePiece := range snakePlayer.snakeBody {
+		snakePlayer.snakeBody[idx].xPos = snakePath[snakePiece.segment].xPos
+		snakePlayer.snakeBody[idx].yPos = snakePath[snakePiece.segment].yPos
+	}
+}
+
+// drawSnake draws the body along its path and then the head
+func drawSnake(screen *ebiten.Image) {
+	// Change pieces depending on current speed
+	// TODO: Make the snake piece white and overlay a rectangle on it dynamically depending on color
+	var pieceColorName string
+	pieceColor, pieceColorName = speedColor()
+
+	if manualColorOverride {
+		pieceColorName = manualColor
+	}
+	tierName := pieceColorName
+	dst, pieceColorName := beginSnakeColors(screen, tierName)
+
+	// Blink the snake after a hit in practice mode
+	snakeHidden := GameState == "game_practice" && practiceSnakeHidden()
+
+	// Draw pieces
+	for _, snakePiece := range snakePlayer.snakeBody {
+		if snakePath[snakePiece.segment].warped {
+			drawWarpMarker(screen, snakePiece.xPos, snakePiece.yPos)
+		}
+		if snakeHidden {
+			continue
+		}
+		if snakePiece.segment == (len(snakePlayer.snakeBody) - 1) {
+			// Tail
+			drawGridPiece(dst, snakePiece.xPos, snakePiece.yPos, ParseHexColor(pieceColor), "snake-tail-"+snakePath[snakePiece.segment].orientation+"-"+pieceColorName, snakePiece.segment)
+		} else {
+			// Other pieces
+			drawGridPiece(dst, snakePiece.xPos, snakePiece.yPos, ParseHexColor(pieceColor), "snake-body-"+snakePath[snakePiece.segment].orientation+"-"+pieceColorName, snakePiece.segment)
+		}
+	}
+
+	// Draw head
+	if !snakeHidden {
+		drawGridPiece(dst, snakePlayer.xPos, snakePlayer.yPos, ParseHexColor(pieceColor), "head-"+snakePlayer.direction+"-"+pieceColorName, 0)
+	}
+
+	cells := []image.Point{}
+	if !snakeHidden {
+		for _, snakePiece := range snakePlayer.snakeBody {
+			cells = append(cells, image.Pt(snakePiece.xPos, snakePiece.yPos))
+		}
+	}
+	endSnakeColors(screen, tierName, cells)
+}
+
+// stepGame moves the run on one tick. Update calls it at the fixed tick
+// rate the game clock counts in, whatever the display draws at.
+func stepGame(g *Game) {
 	// FX for apple
 	doAppleScale()
 
 	// FX for body
-	if !GameOver {
+	if !gameOver() {
 		doBodyFactor()
 	}
 
-	// Show score count
-	showScore(screen)
-
-	// Hard mode
-	if GameState == "game_hard" {
-		text.Draw(screen, "Hard Mode", timerFont, (ScreenWidth/3)-330, (int(math.Round(borderTop / 1.5))), ParseHexColor("#749e35"))
-	} else {
-		text.Draw(screen, "Normal Mode", timerFont, (ScreenWidth/3)-330, (int(math.Round(borderTop / 1.5))), ParseHexColor("#749e35"))
+	// Put out an apple before the snake moves, so runs replay the same under package rules
+	if gameStarted() && !nomActive {
+		spawnNom()
 	}
-	// Handle game started vs paused
-	if GameStarted && !GamePaused && !GameOver {
+
+	// Handle game started vs paused, slower game speeds skip some frames
+	moved := false
+	simulate := simulationFrame()
+	if simulate && gameStarted() && !gamePaused() {
 		var moveCounter int
+		runRamp.Clock.Tick()
 		if g.clockSpeedCount == 0 {
 			moveCounter = 1
-			// Show timer
-			doTimer()
 		} else {
 			moveCounter = 0
 		}
@@ -1183,54 +1368,37 @@ func doGame(g *Game, screen *ebiten.Image) {
 		if snakePlayer.direction == "right" {
 			snakePlayer.xPos += moveCounter
 		}
-	}
 
-	// Change pieces depending on current speed
-	var pieceColorName string
-	// TODO: Make the snake piece white and overlay a rectangle on it dynamically depending on color
-	switch speed := clockSpeedHuman; {
-	case speed >= 7:
-		pieceColor = "#ff3c3c" // RED
-		pieceColorName = "red"
-	case speed >= 3:
-		pieceColor = "#ff9300" // ORANGE
-		pieceColorName = "orange"
-	default:
-		pieceColor = "#8bc03c" // GREEN
-		pieceColorName = "green"
-	}
+		// Come out the other side instead of hitting the wall
+		if wrapWalls {
+			wrapHead()
+		}
 
-	if manualColorOverride {
-		pieceColorName = manualColor
-	}
+		// Jump through a portal if the head just landed on one
+		if moveCounter == 1 && doPortal() {
+			headWarped = true
+		}
 
-	// Draw pieces
-	for idx, snakePiece := range snakePlayer.snakeBody {
-		snakePlayer.snakeBody[idx].xPos = snakePath[snakePiece.segment].xPos
-		snakePlayer.snakeBody[idx].yPos = snakePath[snakePiece.segment].yPos
-		if snakePiece.segment == (len(snakePlayer.snakeBody) - 1) {
-			// Tail
-			drawGridPiece(screen, snakePiece.xPos, snakePiece.yPos, ParseHexColor(pieceColor), "snake-tail-"+snakePath[snakePiece.segment].orientation+"-"+pieceColorName, snakePiece.segment)
-		} else {
-			// Other pieces
-			drawGridPiece(screen, snakePiece.xPos, snakePiece.yPos, ParseHexColor(pieceColor), "snake-body-"+snakePath[snakePiece.segment].orientation+"-"+pieceColorName, snakePiece.segment)
+		// Hazards move along with the snake
+		if moveCounter == 1 {
+			doHazards()
+			recordMove()
+			snakeHeading = snakePlayer.direction
+			moved = true
 		}
 	}
 
-	// Draw head
-	drawGridPiece(screen, snakePlayer.xPos, snakePlayer.yPos, ParseHexColor(pieceColor), "head-"+snakePlayer.direction+"-"+pieceColorName, 0)
-
-	// Draw noms
-	if GameStarted {
-		doNoms(g, screen)
+	// Eat the apple if the head is on it
+	if gameStarted() {
+		doNoms()
 	}
 
 	// Update clock speed count
-	// TODO: Is there some way to control game fps or clock speed or ticks in ebitengine?
-
-	g.clockSpeedCount += 1
-	if g.clockSpeedCount > clockSpeed {
-		if GameStarted && !GamePaused {
+	if simulate {
+		g.clockSpeedCount += 1
+	}
+	if g.clockSpeedCount > runRamp.Speed {
+		if gameStarted() && !gamePaused() {
 			var orientation string
 			if snakePlayer.direction == "up" || snakePlayer.direction == "down" {
 				orientation = "vertical"
@@ -1238,10 +1406,12 @@ func doGame(g *Game, screen *ebiten.Image) {
 			if snakePlayer.direction == "left" || snakePlayer.direction == "right" {
 				orientation = "horizontal"
 			}
-			snakePath = append([]pathPair{{snakePlayer.xPos, snakePlayer.yPos, orientation}}, snakePath[0:len(snakePlayer.snakeBody)]...)
+			snakePath = append([]pathPair{{snakePlayer.xPos, snakePlayer.yPos, orientation, headWarped}}, snakePath[0:len(snakePlayer.snakeBody)]...)
+			headWarped = false
 		}
 		g.clockSpeedCount = 0
 	}
+	placeBody()
 
 	// Check collision paths to end game
 	for idx, snakePathPair := range snakePath {
@@ -1249,87 +1419,62 @@ func doGame(g *Game, screen *ebiten.Image) {
 		if idx != 0 {
 			// Did the snake collide with itself?
 			if snakePlayer.xPos == snakePathPair.xPos && snakePlayer.yPos == snakePathPair.yPos {
-				GameStarted = false
-				GameOver = true
-				GameJustEnded = true
+				snakeCrashed()
 			}
 		}
 		// Check if the head collided with a wall
 		if idx == 0 {
-			if snakePlayer.xPos >= gridWidth || snakePlayer.xPos < 0 || snakePlayer.yPos >= gridHeight || snakePlayer.yPos < 0 {
-				GameStarted = false
-				GameOver = true
-				GameJustEnded = true
+			if snakePlayer.xPos >= gridWidth || snakePlayer.xPos < 0 || snakePlayer.yPos >= gridHeight || snakePlayer.yPos < 0 ||
+				isLevelWall(snakePlayer.xPos, snakePlayer.yPos) {
+				snakeCrashed()
 			}
 		}
 	}
 
-	text.Draw(screen, "Seconds Survived: "+strconv.Itoa(timeElapsed), timerFont, (ScreenWidth/3)-40, (int(math.Round(borderTop / 1.5))), color.White)
-	text.Draw(screen, "Current Speed: "+strconv.Itoa(clockSpeedHuman), timerFont, (ScreenWidth/3)+480, (int(math.Round(borderTop / 1.5))), color.White)
-
-	// Show Game Over
-	if GameOver {
-		// TODO: One day we use JSON to get a scoreboard going, or a database
-		// doScoreboard()
-		// text.Draw(screen, scoreBoard, baseFont, (ScreenWidth/2)-200, (ScreenHeight/2)-50, color.White)
-		drawBlackOverlay(screen)
-		drawSnakeDead(screen)
-		text.Draw(screen, "Womp womp. Game over.\n\nEnter = New Game\nM = Change mode\nEscape = Quit",
-			baseFont, (ScreenWidth/2)-200, (ScreenHeight/2)-50, color.White,
-		)
-		timerTicker.Stop()
-	}
-
-	// Handle game started vs paused
-	if GameStarted && GamePaused {
-		drawBlackOverlay(screen)
-		text.Draw(screen, "Game Paused. Escape to resume\nor Q to quit.", baseFont, (ScreenWidth/3)-56, (ScreenHeight/3)+90, color.White)
-	} else if !GameStarted && !GameOver {
-		// Do not update snake
-		// Show start text
-		drawBlackOverlay(screen)
-		text.Draw(screen, "Arrow keys or WASD keys move snake\nEnter starts game", baseFont, (ScreenWidth/3)-70, (ScreenHeight/3)+130, color.White)
+	// Check if the arena closed in on the snake
+	if GameState == "game_shrink" {
+		doShrink()
 	}
 
-	// Handle game over sound
-	if GameOver && GameJustEnded && !GameOverSndPlaying {
+	// Keep the game over sound going, unless it was muted when the snake crashed
+	if gameOver() && GameOverSndPlaying {
 		if !muted {
-			GameOverSndPlaying = true
-			gameOverSnd.Seek(0)
 			gameOverSnd.Play()
 		}
 	}
-	if GameOver && GameOverSndPlaying {
-		if !muted {
-			gameOverSnd.Play()
-		}
+
+	// Keep each move for GIFs
+	if moved {
+		captureFrame()
 	}
 
+	// Stream to anyone watching
+	publishSpectatorFrame()
 }
 
-func handleGameState(g *Game, screen *ebiten.Image) {
-
-	// TODO: Maybe make this cleaner
-	if GameState == "intro" {
-		doIntro(g, screen)
-	}
+// doGame draws the run as it stands, stepGame moves it
+func doGame(g *Game, screen *ebiten.Image) {
+	drawBoard(screen)
+	drawHUD(screen)
+	drawSnake(screen)
 
-	if GameState == "title" {
-		doTitle(g, screen)
-	}
+	// Draw hazards
+	drawHazards(screen)
 
-	if GameState == "game" {
-		doGame(g, screen)
+	// Coordinates for practice
+	if GameState == "game_practice" && showGridCoords {
+		drawGridCoords(screen)
 	}
 
-	if GameState == "game_hard" {
-		doGame(g, screen)
+	// Draw noms
+	if gameStarted() && nomActive {
+		drawNom(screen)
 	}
 
-	if GameState == "exit" {
-		os.Exit(0)
+	// Carry on with a GIF being saved
+	if gameOver() {
+		doExport(screen)
 	}
-
 }
 
 func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
@@ -1340,5 +1485,10 @@ func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
-	handleGameState(g, screen)
+	drawScenes(g, screen)
+	drawDebug(g, screen)
+	if screenshotWanted {
+		takeScreenshot(screen)
+	}
+	drawScreenshotNote(screen)
 }
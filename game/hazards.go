@@ -0,0 +1,237 @@
+package game
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	hazardBlock = "block" // patrols back and forth in a straight line
+	hazardBall  = "ball"  // bounces diagonally off walls
+	hazardMouse = "mouse" // runs away from the snake head, eat it for a bonus
+
+	mouseBonus        = 5
+	mouseRespawnMoves = 40
+)
+
+type hazardSpawn struct {
+	kind       string
+	xPos, yPos int
+	xDir, yDir int
+}
+
+// hazard is a moving piece on the board. Hazards only move when the snake
+// moves, every interval snake moves, so their path depends on nothing but
+// the moves made so far and a replay of the same moves plays out the same.
+type hazard struct {
+	kind       string
+	xPos, yPos int
+	xDir, yDir int
+	interval   int
+	tickCount  int
+	alive      bool
+	respawnIn  int
+	spawn      hazardSpawn
+}
+
+var (
+	hazards []*hazard
+)
+
+// hazardSpawnForGlyph maps the level file glyphs onto hazards
+func hazardSpawnForGlyph(glyph rune, ix int, iy int) (hazardSpawn, bool) {
+	switch glyph {
+	case 'H':
+		return hazardSpawn{hazardBlock, ix, iy, 1, 0}, true
+	case 'V':
+		return hazardSpawn{hazardBlock, ix, iy, 0, 1}, true
+	case 'B':
+		return hazardSpawn{hazardBall, ix, iy, 1, 1}, true
+	case 'm':
+		return hazardSpawn{hazardMouse, ix, iy, 0, 0}, true
+	}
+	return hazardSpawn{}, false
+}
+
+func hazardInterval(kind string) int {
+	switch kind {
+	case hazardBall:
+		return 2
+	case hazardMouse:
+		return 2
+	}
+	return 3
+}
+
+// resetHazards puts every hazard of the current level back on its spawn
+func resetHazards() {
+	hazards = nil
+	lvl := activeLevel()
+	if lvl == nil {
+		return
+	}
+	for _, spawn := range lvl.hazards {
+		hazards = append(hazards, &hazard{
+			kind:     spawn.kind,
+			xPos:     spawn.xPos,
+			yPos:     spawn.yPos,
+			xDir:     spawn.xDir,
+			yDir:     spawn.yDir,
+			interval: hazardInterval(spawn.kind),
+			alive:    true,
+			spawn:    spawn,
+		})
+	}
+}
+
+// isHazardBlocked reports whether a hazard can't move onto the cell
+func isHazardBlocked(ix int, iy int, self *hazard) bool {
+	if ix < 0 || iy < 0 || ix >= gridWidth || iy >= gridHeight {
+		return true
+	}
+	if isLevelWall(ix, iy) {
+		return true
+	}
+	if _, onPortal := portalPair(ix, iy); onPortal {
+		return true
+	}
+	for _, other := range hazards {
+		if other != self && other.alive && other.xPos == ix && other.yPos == iy {
+			return true
+		}
+	}
+	return false
+}
+
+// isSnakeCell reports whether the head or a body piece is on the cell
+func isSnakeCell(ix int, iy int) bool {
+	if snakePlayer.xPos == ix && snakePlayer.yPos == iy {
+		return true
+	}
+	for idx := 0; idx < len(snakePlayer.snakeBody) && idx < len(snakePath); idx++ {
+		if snakePath[idx].xPos == ix && snakePath[idx].yPos == iy {
+			return true
+		}
+	}
+	return false
+}
+
+func moveBlock(h *hazard) {
+	if isHazardBlocked(h.xPos+h.xDir, h.yPos+h.yDir, h) {
+		h.xDir, h.yDir = -h.xDir, -h.yDir
+	}
+	if !isHazardBlocked(h.xPos+h.xDir, h.yPos+h.yDir, h) {
+		h.xPos += h.xDir
+		h.yPos += h.yDir
+	}
+}
+
+func moveBall(h *hazard) {
+	if isHazardBlocked(h.xPos+h.xDir, h.yPos, h) {
+		h.xDir = -h.xDir
+	}
+	if isHazardBlocked(h.xPos, h.yPos+h.yDir, h) {
+		h.yDir = -h.yDir
+	}
+	if isHazardBlocked(h.xPos+h.xDir, h.yPos+h.yDir, h) {
+		// Corner hit, straight back the way it came
+		h.xDir, h.yDir = -h.xDir, -h.yDir
+	}
+	if !isHazardBlocked(h.xPos+h.xDir, h.yPos+h.yDir, h) {
+		h.xPos += h.xDir
+		h.yPos += h.yDir
+	}
+}
+
+// moveMouse steps to whichever neighbouring cell is furthest from the head.
+// Ties go to the first option in a fixed order so the choice never varies.
+func moveMouse(h *hazard) {
+	options := []gridCell{
+		{h.xPos, h.yPos - 1},
+		{h.xPos + 1, h.yPos},
+		{h.xPos, h.yPos + 1},
+		{h.xPos - 1, h.yPos},
+	}
+	best := gridCell{h.xPos, h.yPos}
+	bestDist := headDistance(best)
+	for _, option := range options {
+		if isHazardBlocked(option.xPos, option.yPos, h) || isSnakeCell(option.xPos, option.yPos) {
+			continue
+		}
+		if dist := headDistance(option); dist > bestDist {
+			best = option
+			bestDist = dist
+		}
+	}
+	h.xPos = best.xPos
+	h.yPos = best.yPos
+}
+
+func headDistance(cell gridCell) int {
+	xDist := cell.xPos - snakePlayer.xPos
+	if xDist < 0 {
+		xDist = -xDist
+	}
+	yDist := cell.yPos - snakePlayer.yPos
+	if yDist < 0 {
+		yDist = -yDist
+	}
+	return xDist + yDist
+}
+
+// doHazards moves the hazards on their own schedules after a snake move,
+// then checks whether anything touched the snake
+func doHazards() {
+	for _, h := range hazards {
+		if !h.alive {
+			h.respawnIn--
+			if h.respawnIn <= 0 && !isSnakeCell(h.spawn.xPos, h.spawn.yPos) && !isHazardBlocked(h.spawn.xPos, h.spawn.yPos, h) {
+				h.xPos = h.spawn.xPos
+				h.yPos = h.spawn.yPos
+				h.alive = true
+				h.tickCount = 0
+			}
+			continue
+		}
+
+		h.tickCount++
+		if h.tickCount >= h.interval {
+			h.tickCount = 0
+			switch h.kind {
+			case hazardBlock:
+				moveBlock(h)
+			case hazardBall:
+				moveBall(h)
+			case hazardMouse:
+				moveMouse(h)
+			}
+		}
+	}
+	checkHazardContact()
+}
+
+func checkHazardContact() {
+	for _, h := range hazards {
+		if !h.alive {
+			continue
+		}
+		if h.kind == hazardMouse {
+			if snakePlayer.xPos == h.xPos && snakePlayer.yPos == h.yPos {
+				h.alive = false
+				h.respawnIn = mouseRespawnMoves
+				currScore += mouseBonus
+			}
+			continue
+		}
+		if isSnakeCell(h.xPos, h.yPos) {
+			snakeCrashed()
+		}
+	}
+}
+
+func drawHazards(screen *ebiten.Image) {
+	for _, h := range hazards {
+		if h.alive {
+			drawGridPiece(screen, h.xPos, h.yPos, ParseHexColor(nomColor), "hazard-"+h.kind, 0)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/brantleyr/go-snake/storage"
+)
+
+const (
+	highScoresFile = "high-scores.json"
+	highScoresKept = 10
+)
+
+type highScore struct {
+	Name    string `json:"name"`
+	Score   int    `json:"score"`
+	Seconds int    `json:"seconds"`
+	Millis  int    `json:"millis,omitempty"`
+	Date    string `json:"date"`
+}
+
+var (
+	highScores   = map[string][]highScore{} // by replay mode
+	newHighScore bool
+)
+
+// time is how long the run took in milliseconds. Scores saved before the
+// clock counted milliseconds only have whole seconds.
+func (h highScore) time() int {
+	if h.Millis == 0 {
+		return h.Seconds * 1000
+	}
+	return h.Millis
+}
+
+func loadHighScores() {
+	data, err := store.Load(highScoresFile)
+	if errors.Is(err, storage.ErrNotFound) {
+		return
+	}
+	if err != nil {
+		log.Println("high scores:", err)
+		return
+	}
+	if err := json.Unmarshal(data, &highScores); err != nil {
+		log.Println("high scores:", err)
+	}
+}
+
+func saveHighScores() {
+	data, err := json.MarshalIndent(highScores, "", "  ")
+	if err != nil {
+		log.Println("high scores:", err)
+		return
+	}
+	if err := store.Save(highScoresFile, data); err != nil {
+		log.Println("high scores:", err)
+	}
+}
+
+// survivalModes are scored on how long the snake lasted, not apples
+var survivalModes = map[string]bool{"shrink": true}
+
+// recordHighScore keeps the run if it makes the top ten for its mode. Ties
+// go to the quicker run, or in survival modes to the one with more apples.
+func recordHighScore(mode string, score int, millis int) {
+	newHighScore = false
+	survival := survivalModes[mode]
+	// Runs slowed down in the accessibility settings aren't up against full speed ones
+	if gameSpeed < 100 || (!survival && score == 0) || (survival && millis == 0) {
+		return
+	}
+	seconds := millis / 1000
+	scores := append(highScores[mode], highScore{playerName(), score, seconds, millis, time.Now().Format("2006-01-02")})
+	sort.SliceStable(scores, func(i, j int) bool {
+		if survival {
+			if scores[i].time() != scores[j].time() {
+				return scores[i].time() > scores[j].time()
+			}
+			return scores[i].Score > scores[j].Score
+		}
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score > scores[j].Score
+		}
+		return scores[i].time() < scores[j].time()
+	})
+	if len(scores) > highScoresKept {
+		scores = scores[:highScoresKept]
+	}
+	for _, kept := range scores {
+		if kept.Score == score && kept.time() == millis {
+			newHighScore = true
+		}
+	}
+	if !newHighScore {
+		return
+	}
+	highScores[mode] = scores
+	saveHighScores()
+}
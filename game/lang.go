@@ -0,0 +1,116 @@
+package game
+
+import (
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/brantleyr/go-snake/assets"
+	"github.com/brantleyr/go-snake/i18n"
+)
+
+const (
+	languageDir     = "lang"
+	defaultLanguage = "en"
+)
+
+var (
+	languages []*i18n.Catalog // sorted by code, for the settings
+	lang      *i18n.Catalog   // what the game is shown in
+)
+
+// loadLanguages reads every catalog in assets/lang. English has every
+// message, the others fall back to it for any they're missing.
+func loadLanguages() {
+	paths, err := fs.Glob(assets.FS, path.Join(languageDir, "*.json"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	sort.Strings(paths)
+
+	var english *i18n.Catalog
+	for _, catalogPath := range paths {
+		src, err := fs.ReadFile(assets.FS, catalogPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		catalog, err := i18n.Parse(src)
+		if err != nil {
+			log.Fatalf("%s: %v", catalogPath, err)
+		}
+		if catalog.Lang == defaultLanguage {
+			english = catalog
+		}
+		languages = append(languages, catalog)
+	}
+	if english == nil {
+		log.Fatalf("%s: no %s.json", languageDir, defaultLanguage)
+	}
+	for _, catalog := range languages {
+		if catalog != english {
+			catalog.Fallback = english
+		}
+	}
+
+	// Until the player picks one, go with the system's, like "es_ES.UTF-8"
+	lang = english
+	if system := os.Getenv("LANG"); len(system) >= 2 {
+		if catalog := languageByCode(system[:2]); catalog != nil {
+			lang = catalog
+		}
+	}
+}
+
+func languageByCode(code string) *i18n.Catalog {
+	for _, catalog := range languages {
+		if strings.EqualFold(catalog.Lang, code) {
+			return catalog
+		}
+	}
+	return nil
+}
+
+// setLanguage switches everything over to the language with code, menus
+// built ahead of time are built again
+func setLanguage(code string) {
+	catalog := languageByCode(code)
+	if catalog == nil {
+		return
+	}
+	lang = catalog
+	buildTitleMenu()
+}
+
+// nextLanguage steps through the languages for the settings menu
+func nextLanguage(step int) {
+	current := 0
+	for idx, catalog := range languages {
+		if catalog == lang {
+			current = idx
+		}
+	}
+	setLanguage(languages[(current+len(languages)+step)%len(languages)].Lang)
+	saveSettings()
+}
+
+// tr is the message for key in the current language
+func tr(key string, args ...interface{}) string {
+	return lang.Text(key, args...)
+}
+
+// trn is tr with the plural form for n
+func trn(key string, n int, args ...interface{}) string {
+	return lang.Plural(key, n, args...)
+}
+
+// num and decimal format numbers the way the current language writes them
+func num(n int) string {
+	return lang.Int(n)
+}
+
+func decimal(f float64, places int) string {
+	return lang.Decimal(f, places)
+}
@@ -0,0 +1,172 @@
+package game
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+
+	"github.com/brantleyr/go-snake/layout"
+	"github.com/brantleyr/go-snake/leaderboard"
+	"github.com/brantleyr/go-snake/replay"
+)
+
+type leaderboardFetch struct {
+	mode    string
+	entries []leaderboard.Entry
+	err     error
+}
+
+var (
+	// LeaderboardURL is the shared leaderboard server, empty keeps scores on this computer
+	LeaderboardURL string
+
+	leaderboardStatus  string
+	leaderboardModes   = []string{"shrink", "level", "daily"} // after the difficulties, see loadDifficulties
+	leaderboardTab     int
+	leaderboardOnline  = map[string][]leaderboard.Entry{}
+	leaderboardError   string
+	leaderboardLoading bool
+
+	// Requests run off the game loop and report back here, Update picks them up
+	leaderboardSubmits = make(chan string, 4)
+	leaderboardFetches = make(chan leaderboardFetch, 4)
+)
+
+// submitScore sends a finished run to the leaderboard in the background
+func submitScore(run *replay.Replay) {
+	leaderboardStatus = ""
+	// Runs slowed down in the accessibility settings only count on this computer
+	if LeaderboardURL == "" || !run.Verifiable() || run.Score == 0 || gameSpeed < 100 {
+		return
+	}
+	leaderboardStatus = tr("leaderboard.sending")
+	sent := *run
+	// The answer is worded in the language the run was played in
+	catalog := lang
+	go func() {
+		rank, err := leaderboard.Submit(LeaderboardURL, playerName(), &sent)
+		if err != nil {
+			leaderboardSubmits <- catalog.Text("leaderboard.error", err.Error())
+			return
+		}
+		leaderboardSubmits <- catalog.Text("leaderboard.rank", catalog.Int(rank))
+	}()
+}
+
+// fetchLeaderboard asks the server for the top scores of the selected mode
+func fetchLeaderboard() {
+	mode := leaderboardMode()
+	leaderboardError = ""
+	if LeaderboardURL == "" || difficultyForMode(mode) == nil {
+		return
+	}
+	leaderboardLoading = true
+	go func() {
+		entries, err := leaderboard.Top(LeaderboardURL, mode, leaderboard.DefaultLimit)
+		leaderboardFetches <- leaderboardFetch{mode, entries, err}
+	}()
+}
+
+// pollLeaderboard applies any answers from the server
+func pollLeaderboard() {
+	for {
+		select {
+		case status := <-leaderboardSubmits:
+			leaderboardStatus = status
+		case fetch := <-leaderboardFetches:
+			leaderboardLoading = false
+			if fetch.err != nil {
+				leaderboardError = fetch.err.Error()
+			} else {
+				leaderboardOnline[fetch.mode] = fetch.entries
+			}
+		default:
+			return
+		}
+	}
+}
+
+func handleLeaderboardKeys() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyRight) || inpututil.IsKeyJustPressed(ebiten.KeyD) {
+		leaderboardTab = (leaderboardTab + 1) % len(leaderboardModes)
+		fetchLeaderboard()
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyLeft) || inpututil.IsKeyJustPressed(ebiten.KeyA) {
+		leaderboardTab = (leaderboardTab + len(leaderboardModes) - 1) % len(leaderboardModes)
+		fetchLeaderboard()
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyEscape) || inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		showScreen("title")
+	}
+}
+
+// leaderboardMode is the selected tab's score table. Custom's is the one for
+// the numbers it's set to now.
+func leaderboardMode() string {
+	mode := leaderboardModes[leaderboardTab]
+	if mode == customDifficulty {
+		return difficultyByID(customDifficulty).mode()
+	}
+	return mode
+}
+
+// leaderboardModeName is what a tab is called, a difficulty or one of the
+// other modes
+func leaderboardModeName(mode string) string {
+	switch mode {
+	case "shrink":
+		return tr("mode.shrink")
+	case "level":
+		return tr("mode.level")
+	case "daily":
+		return tr("menu.daily")
+	}
+	if d := difficultyForMode(mode); d != nil {
+		return difficultyName(d)
+	}
+	return mode
+}
+
+// leaderboardLine is one score, numbered from 1
+func leaderboardLine(idx int, name string, score, seconds int) string {
+	return tr("leaderboard.line", num(idx+1), name, num(score), num(seconds))
+}
+
+func doLeaderboard(g *Game, screen *ebiten.Image) {
+	drawBg(screen)
+	drawBlackOverlay(screen)
+
+	mode := leaderboardMode()
+	heading := drawHeading(screen, tr("leaderboard.heading"))
+	drawText(screen, "<  "+leaderboardModeName(mode)+"  >", baseFont, under(heading), layout.Top, ParseHexColor("#749e35"))
+
+	left := 90
+	right := (ScreenWidth / 2) + 40
+	text.Draw(screen, tr("leaderboard.local"), baseFont, left, 250, ParseHexColor("#8c8c8c"))
+	for idx, score := range highScores[mode] {
+		text.Draw(screen, leaderboardLine(idx, score.Name, score.Score, score.Seconds), timerFont, left, 290+(idx*36), color.White)
+	}
+	if len(highScores[mode]) == 0 {
+		text.Draw(screen, tr("leaderboard.none"), timerFont, left, 290, color.White)
+	}
+
+	text.Draw(screen, tr("leaderboard.online"), baseFont, right, 250, ParseHexColor("#8c8c8c"))
+	switch {
+	case LeaderboardURL == "":
+		text.Draw(screen, tr("leaderboard.no_server"), timerFont, right, 290, color.White)
+	case difficultyForMode(mode) == nil:
+		text.Draw(screen, tr("leaderboard.unverifiable"), timerFont, right, 290, color.White)
+	case leaderboardError != "":
+		text.Draw(screen, leaderboardError, timerFont, right, 290, ParseHexColor(nomColor))
+	case leaderboardLoading:
+		text.Draw(screen, tr("leaderboard.loading"), timerFont, right, 290, color.White)
+	case len(leaderboardOnline[mode]) == 0:
+		text.Draw(screen, tr("leaderboard.none"), timerFont, right, 290, color.White)
+	default:
+		for idx, entry := range leaderboardOnline[mode] {
+			text.Draw(screen, leaderboardLine(idx, entry.Name, entry.Score, entry.Seconds), timerFont, right, 290+(idx*36), color.White)
+		}
+	}
+
+	drawFooter(screen, tr("leaderboard.keys"))
+}
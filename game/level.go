@@ -0,0 +1,192 @@
+package game
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/brantleyr/go-snake/assets"
+)
+
+const (
+	levelDir        = "levels"
+	levelWallColor  = "#3c5a1e"
+	levelFloorGlyph = '.'
+	levelWallGlyph  = '#'
+)
+
+type gridCell struct {
+	xPos, yPos int
+}
+
+// level is a board layout loaded from a text file in assets/levels.
+//
+// Each file has an optional "name: ..." header, lines starting with ";" are
+// comments, and then exactly gridHeight rows of gridWidth glyphs:
+//
+//	.    empty floor
+//	#    wall
+//	0-9  portal, each digit must appear exactly twice to make a pair
+//	H    block patrolling left and right
+//	V    block patrolling up and down
+//	B    bouncing ball
+//	m    mouse, runs from the snake and is worth bonus points
+//
+// The snake always starts in the top left corner heading down, so the
+// first few cells of the left column need to stay clear.
+type level struct {
+	name    string
+	walls   map[gridCell]bool
+	portals map[gridCell]gridCell // each portal cell maps to its pair
+	digits  map[gridCell]int      // the digit each portal was drawn with, for its color
+	hazards []hazardSpawn         // in row order, so they always move in the same order
+}
+
+var (
+	levels       []*level
+	levelIndex   = 0
+	currentLevel *level
+	dailyLevel   *level
+)
+
+func loadLevels() {
+	paths, err := fs.Glob(assets.FS, path.Join(levelDir, "*.txt"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	sort.Strings(paths)
+
+	for _, levelPath := range paths {
+		src, err := fs.ReadFile(assets.FS, levelPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		lvl, err := parseLevel(string(src))
+		if err != nil {
+			log.Fatalf("%s: %v", levelPath, err)
+		}
+		if lvl.name == "" {
+			lvl.name = strings.TrimSuffix(path.Base(levelPath), ".txt")
+		}
+		levels = append(levels, lvl)
+	}
+}
+
+func parseLevel(src string) (*level, error) {
+	lvl := &level{
+		walls:   map[gridCell]bool{},
+		portals: map[gridCell]gridCell{},
+		digits:  map[gridCell]int{},
+	}
+	portalEnds := map[rune][]gridCell{}
+
+	iy := 0
+	for lineNum, line := range strings.Split(src, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "name:") {
+			lvl.name = strings.TrimSpace(strings.TrimPrefix(line, "name:"))
+			continue
+		}
+		if iy >= gridHeight {
+			return nil, fmt.Errorf("line %d: more than %d rows", lineNum+1, gridHeight)
+		}
+		if len(line) != gridWidth {
+			return nil, fmt.Errorf("line %d: row is %d cells wide, want %d", lineNum+1, len(line), gridWidth)
+		}
+		for ix, glyph := range line {
+			cell := gridCell{ix, iy}
+			switch {
+			case glyph == levelFloorGlyph:
+			case glyph == levelWallGlyph:
+				lvl.walls[cell] = true
+			case glyph >= '0' && glyph <= '9':
+				portalEnds[glyph] = append(portalEnds[glyph], cell)
+				lvl.digits[cell] = int(glyph - '0')
+			default:
+				spawn, ok := hazardSpawnForGlyph(glyph, ix, iy)
+				if !ok {
+					return nil, fmt.Errorf("line %d: unknown glyph %q", lineNum+1, glyph)
+				}
+				lvl.hazards = append(lvl.hazards, spawn)
+			}
+		}
+		iy++
+	}
+	if iy != gridHeight {
+		return nil, fmt.Errorf("got %d rows, want %d", iy, gridHeight)
+	}
+
+	for glyph, ends := range portalEnds {
+		if len(ends) != 2 {
+			return nil, fmt.Errorf("portal %q appears %d times, want 2", glyph, len(ends))
+		}
+		lvl.portals[ends[0]] = ends[1]
+		lvl.portals[ends[1]] = ends[0]
+	}
+
+	return lvl, nil
+}
+
+// nextLevel moves on to the next level, wrapping around after the last one
+func nextLevel() {
+	if len(levels) == 0 {
+		return
+	}
+	levelIndex = (levelIndex + 1) % len(levels)
+	currentLevel = levels[levelIndex]
+	resetHazards()
+}
+
+// activeLevel is the layout being played, if the current mode has one
+func activeLevel() *level {
+	if GameState == "game_level" {
+		return currentLevel
+	}
+	if GameState == "game_daily" {
+		return dailyLevel
+	}
+	return nil
+}
+
+// isLevelWall reports whether the cell is a wall in the level being played
+func isLevelWall(ix int, iy int) bool {
+	lvl := activeLevel()
+	if lvl == nil {
+		return false
+	}
+	return lvl.walls[gridCell{ix, iy}]
+}
+
+// isBlockedCell reports whether an apple can't be placed on the cell
+func isBlockedCell(ix int, iy int) bool {
+	if isClosedCell(ix, iy) || isLevelWall(ix, iy) {
+		return true
+	}
+	if _, onPortal := portalPair(ix, iy); onPortal {
+		return true
+	}
+	for _, h := range hazards {
+		if h.alive && h.xPos == ix && h.yPos == iy {
+			return true
+		}
+	}
+	return false
+}
+
+func drawLevelWalls(screen *ebiten.Image) {
+	lvl := activeLevel()
+	if lvl == nil {
+		return
+	}
+	for cell := range lvl.walls {
+		drawGridPiece(screen, cell.xPos, cell.yPos, ParseHexColor(levelWallColor), "rect", 0)
+	}
+}
@@ -0,0 +1,271 @@
+package game
+
+import (
+	"image"
+	"image/color"
+
+	"golang.org/x/image/font"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+
+	"github.com/brantleyr/go-snake/layout"
+)
+
+// menuItem is one line of a menu. Choosing it runs action, or opens the
+// submenu in its place.
+type menuItem struct {
+	label   string
+	action  func()
+	enabled func() bool // nil is always enabled
+	submenu *menu
+	adjust  func(step int) // Left/Right change the item's value, step is -1 or 1
+}
+
+func (item menuItem) isEnabled() bool {
+	return item.enabled == nil || item.enabled()
+}
+
+// menu is a list of items picked with the keyboard, a gamepad or the mouse.
+// It's drawn centered and scrolls when there are more items than fit.
+type menu struct {
+	items    []menuItem
+	selected int
+	scroll   int // first item shown
+	visible  int // items shown at once, 0 shows them all
+
+	open    *menu             // submenu open in this one's place
+	rows    []image.Rectangle // where the shown items were last drawn, for the mouse
+	mouseAt image.Point
+}
+
+func newMenu(items ...menuItem) *menu {
+	m := &menu{}
+	m.setItems(items)
+	return m
+}
+
+// setItems swaps the items, keeping the selection where it can. Menus with
+// labels that change, like settings, set theirs again each update.
+func (m *menu) setItems(items []menuItem) {
+	m.items = items
+	if m.selected >= len(items) {
+		m.selected = 0
+	}
+	if !m.canSelect(m.selected) {
+		m.move(1)
+	}
+	m.keepSelectedShown()
+}
+
+// reset closes any submenu and selects the first item
+func (m *menu) reset() {
+	m.open = nil
+	m.selected = 0
+	m.scroll = 0
+	m.setItems(m.items)
+}
+
+// current is the menu that has the keys, the deepest open submenu
+func (m *menu) current() *menu {
+	if m.open != nil {
+		return m.open.current()
+	}
+	return m
+}
+
+// close backs out of the deepest submenu, it's false when there's none open
+func (m *menu) close() bool {
+	if m.open == nil {
+		return false
+	}
+	if !m.open.close() {
+		m.open = nil
+	}
+	return true
+}
+
+func (m *menu) canSelect(idx int) bool {
+	return idx >= 0 && idx < len(m.items) && m.items[idx].isEnabled()
+}
+
+// move selects the next enabled item up or down, wrapping around the ends
+func (m *menu) move(step int) {
+	for tries := 0; tries < len(m.items); tries++ {
+		m.selected = (m.selected + step + len(m.items)) % len(m.items)
+		if m.canSelect(m.selected) {
+			break
+		}
+	}
+	m.keepSelectedShown()
+}
+
+func (m *menu) keepSelectedShown() {
+	if m.visible == 0 || len(m.items) <= m.visible {
+		m.scroll = 0
+		return
+	}
+	if m.selected < m.scroll {
+		m.scroll = m.selected
+	} else if m.selected >= m.scroll+m.visible {
+		m.scroll = m.selected - m.visible + 1
+	}
+}
+
+func (m *menu) choose(idx int) {
+	if !m.canSelect(idx) {
+		return
+	}
+	m.selected = idx
+	item := m.items[idx]
+	if item.submenu != nil {
+		item.submenu.reset()
+		m.open = item.submenu
+	} else if item.action != nil {
+		item.action()
+	}
+}
+
+// update handles the keys for the menu and its submenus. It's true when the
+// player backs out of the menu itself, what that means is up to its owner.
+func (m *menu) update() bool {
+	current := m.current()
+	if len(current.items) == 0 {
+		return menuBackPressed()
+	}
+
+	if menuUpPressed() {
+		current.move(-1)
+	} else if menuDownPressed() {
+		current.move(1)
+	} else if step := menuSidePressed(); step != 0 {
+		if item := current.items[current.selected]; item.adjust != nil && item.isEnabled() {
+			item.adjust(step)
+		}
+	} else if menuChoosePressed() {
+		current.choose(current.selected)
+	} else if menuBackPressed() {
+		return !m.close()
+	}
+	current.updateMouse()
+	return false
+}
+
+// updateMouse selects whatever the mouse moves over, and chooses it on a click
+func (m *menu) updateMouse() {
+	if _, wheel := ebiten.Wheel(); wheel != 0 && m.visible > 0 && len(m.items) > m.visible {
+		if wheel > 0 && m.scroll > 0 {
+			m.scroll--
+		} else if wheel < 0 && m.scroll+m.visible < len(m.items) {
+			m.scroll++
+		}
+	}
+
+	cursor := image.Pt(ebiten.CursorPosition())
+	moved := cursor != m.mouseAt
+	m.mouseAt = cursor
+	for row, rect := range m.rows {
+		idx := m.scroll + row
+		if !cursor.In(rect) || !m.canSelect(idx) {
+			continue
+		}
+		// A mouse sitting still doesn't take the selection from the keys
+		if moved {
+			m.selected = idx
+		}
+		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+			m.choose(idx)
+		}
+		return
+	}
+}
+
+// draw draws the open menu down from the top of the area, each item
+// centered in it, and gives back where it went
+func (m *menu) draw(screen *ebiten.Image, face font.Face, area image.Rectangle, rowHeight int) image.Rectangle {
+	current := m.current()
+	current.rows = current.rows[:0]
+
+	first, last := 0, len(current.items)
+	if current.visible > 0 && len(current.items) > current.visible {
+		first, last = current.scroll, current.scroll+current.visible
+	}
+	row := func(idx int) image.Rectangle {
+		top := area.Min.Y + (idx-first)*rowHeight
+		return image.Rect(area.Min.X, top, area.Max.X, top+rowHeight)
+	}
+
+	grey := ParseHexColor("#8c8c8c")
+	drawn := image.Rectangle{}
+	for idx := first; idx < last; idx++ {
+		item := current.items[idx]
+		line := layout.Place(face, item.label, row(idx), layout.Center, 0)[0]
+
+		var itemColor color.Color = grey
+		if !item.isEnabled() {
+			itemColor = ParseHexColorAlpha("#8c8c8c", 0x66)
+		} else if idx == current.selected {
+			itemColor = color.White
+			text.Draw(screen, ">", face, line.Dot.X-layout.Width(face, "> "), line.Dot.Y, itemColor)
+		}
+		text.Draw(screen, line.Text, face, line.Dot.X, line.Dot.Y, itemColor)
+
+		// The whole row is clickable, not just the letters
+		bounds := layout.Bounds(face, []layout.Line{line})
+		current.rows = append(current.rows, image.Rect(bounds.Min.X-20, row(idx).Min.Y, bounds.Max.X+20, row(idx).Max.Y))
+		drawn = drawn.Union(current.rows[len(current.rows)-1])
+	}
+
+	// Show there's more to scroll to
+	if first > 0 {
+		drawText(screen, "^", face, row(first-1), layout.Center, grey)
+	}
+	if last < len(current.items) {
+		drawn = drawn.Union(drawText(screen, "v", face, row(last), layout.Center, grey))
+	}
+	return drawn
+}
+
+// Menus take the arrow keys, WASD and a gamepad's d-pad and face buttons
+
+func menuUpPressed() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyUp) || inpututil.IsKeyJustPressed(ebiten.KeyW) ||
+		gamepadJustPressed(ebiten.StandardGamepadButtonLeftTop)
+}
+
+func menuDownPressed() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyDown) || inpututil.IsKeyJustPressed(ebiten.KeyS) ||
+		gamepadJustPressed(ebiten.StandardGamepadButtonLeftBottom)
+}
+
+// menuSidePressed is -1 for left, 1 for right and 0 for neither
+func menuSidePressed() int {
+	if inpututil.IsKeyJustPressed(ebiten.KeyLeft) || inpututil.IsKeyJustPressed(ebiten.KeyA) ||
+		gamepadJustPressed(ebiten.StandardGamepadButtonLeftLeft) {
+		return -1
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyRight) || inpututil.IsKeyJustPressed(ebiten.KeyD) ||
+		gamepadJustPressed(ebiten.StandardGamepadButtonLeftRight) {
+		return 1
+	}
+	return 0
+}
+
+func menuChoosePressed() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyEnter) || gamepadJustPressed(ebiten.StandardGamepadButtonRightBottom)
+}
+
+func menuBackPressed() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyEscape) || gamepadJustPressed(ebiten.StandardGamepadButtonRightRight)
+}
+
+// gamepadJustPressed checks every connected gamepad with a standard layout
+func gamepadJustPressed(button ebiten.StandardGamepadButton) bool {
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if ebiten.IsStandardGamepadLayoutAvailable(id) && inpututil.IsStandardGamepadButtonJustPressed(id, button) {
+			return true
+		}
+	}
+	return false
+}
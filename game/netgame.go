@@ -0,0 +1,246 @@
+package game
+
+import (
+	"image"
+	"image/color"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+
+	"github.com/brantleyr/go-snake/layout"
+	"github.com/brantleyr/go-snake/netplay"
+)
+
+var (
+	netServer      *netplay.Server
+	netClient      *netplay.Client
+	netError       string
+	netJoinAddress = "127.0.0.1:" + netplay.DefaultPort
+	netColors      = []string{"green", "orange", "red"}
+)
+
+func playerName() string {
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	if name := os.Getenv("USERNAME"); name != "" {
+		return name
+	}
+	return "Player"
+}
+
+// hostGame starts a server and joins it like any other player
+func hostGame() {
+	var err error
+	netError = ""
+	netServer, err = netplay.Listen(":" + netplay.DefaultPort)
+	if err != nil {
+		netError = err.Error()
+		showScreen("net_join")
+		return
+	}
+	_, port, _ := net.SplitHostPort(netServer.Addr())
+	netClient, err = netplay.Dial("127.0.0.1:"+port, playerName())
+	if err != nil {
+		netError = err.Error()
+		leaveNetGame()
+		showScreen("net_join")
+		return
+	}
+	showScreen("net_lobby")
+}
+
+func joinGame() {
+	var err error
+	netError = ""
+	netClient, err = netplay.Dial(netJoinAddress, playerName())
+	if err != nil {
+		netError = err.Error()
+		return
+	}
+	showScreen("net_lobby")
+}
+
+func leaveNetGame() {
+	if netClient != nil {
+		netClient.Close()
+		netClient = nil
+	}
+	if netServer != nil {
+		netServer.Close()
+		netServer = nil
+	}
+}
+
+func handleNetJoinKeys() {
+	netJoinAddress = string(ebiten.AppendInputChars([]rune(netJoinAddress)))
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(netJoinAddress) > 0 {
+		netJoinAddress = netJoinAddress[:len(netJoinAddress)-1]
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		joinGame()
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		netError = ""
+		showScreen("title")
+	}
+}
+
+func handleNetLobbyKeys() {
+	netClient.Poll()
+	if netClient.Err != nil {
+		netError = netClient.Err.Error()
+		leaveNetGame()
+		showScreen("net_join")
+		return
+	}
+	if netClient.Phase == netplay.PhasePlaying {
+		showScreen("net_game")
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) && netClient.IsHost() {
+		netClient.Start()
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		leaveNetGame()
+		showScreen("title")
+	}
+}
+
+func handleNetGameKeys() {
+	netClient.Poll()
+	if netClient.Err != nil {
+		netError = netClient.Err.Error()
+		leaveNetGame()
+		showScreen("net_join")
+		return
+	}
+
+	if netClient.Phase == netplay.PhaseEnded {
+		if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+			netClient.Phase = netplay.PhaseLobby
+			showScreen("net_lobby")
+		} else if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+			leaveNetGame()
+			showScreen("title")
+		}
+		return
+	}
+
+	// The server only knows absolute directions, turns are from the snake's
+	// direction with any turns still on their way to the server
+	for _, snake := range netClient.Snakes() {
+		if snake.ID == netClient.SnakeID() {
+			if next := steerPressed(snake.Direction, snake.Direction); next != "" {
+				netClient.Turn(next)
+			}
+		}
+	}
+}
+
+func doNetJoin(g *Game, screen *ebiten.Image) {
+	drawBg(screen)
+	drawBlackOverlay(screen)
+	drawn := drawHeading(screen, tr("menu.join"))
+	drawn = drawText(screen, tr("net.address"), baseFont, layout.Inset(under(drawn), textPadding), layout.Top, ParseHexColor("#8c8c8c"))
+	drawn = drawText(screen, netJoinAddress+"_", baseFont, under(drawn), layout.Top, color.White)
+	if netError != "" {
+		drawText(screen, netError, timerFont, under(drawn), layout.Top, ParseHexColor(nomColor))
+	}
+	drawFooter(screen, tr("net.join_keys"))
+}
+
+func doNetLobby(g *Game, screen *ebiten.Image) {
+	drawBg(screen)
+	drawBlackOverlay(screen)
+	drawn := drawHeading(screen, tr("net.lobby"))
+	if netServer != nil {
+		drawn = drawText(screen, tr("net.hosting", netplay.DefaultPort), timerFont, under(drawn), layout.Top, ParseHexColor("#749e35"))
+	}
+
+	players := []string{}
+	for idx, player := range netClient.Players {
+		name := player.Name
+		if player.Slot == netClient.Slot {
+			name = tr("net.you", name)
+		}
+		players = append(players, num(idx+1)+". "+name)
+	}
+	drawText(screen, strings.Join(players, "\n"), baseFont, layout.Inset(under(drawn), textPadding), layout.Top, color.White)
+
+	help := tr("net.waiting")
+	if netClient.IsHost() {
+		help = tr("net.host_keys", netplay.MinPlayers, netplay.MaxPlayers)
+	}
+	drawFooter(screen, help)
+}
+
+// drawNetSnake draws a snake from the server, picking each piece's sprite
+// from where its neighbours are since the pieces have no path of their own
+func drawNetSnake(screen *ebiten.Image, snake netplay.SnakeState, colorName string) {
+	// Every snake shares the arena's speed, which ramps up like Normal
+	tierName := difficultyByID("normal").Tier(netClient.State.Speed).Name
+	dst, colorName := beginSnakeColors(screen, colorName)
+	cells := []image.Point{}
+	for _, piece := range snake.Body {
+		cells = append(cells, image.Pt(piece.X, piece.Y))
+	}
+	defer endSnakeColors(screen, tierName, cells)
+
+	for idx := len(snake.Body) - 1; idx >= 1; idx-- {
+		orientation := "vertical"
+		if snake.Body[idx].Y == snake.Body[idx-1].Y {
+			orientation = "horizontal"
+		}
+		part := "snake-body-"
+		if idx == len(snake.Body)-1 {
+			part = "snake-tail-"
+		}
+		drawGridPiece(dst, snake.Body[idx].X, snake.Body[idx].Y, ParseHexColor(pieceColor), part+orientation+"-"+colorName, idx)
+	}
+	drawGridPiece(dst, snake.Body[0].X, snake.Body[0].Y, ParseHexColor(pieceColor), "head-"+snake.Direction+"-"+colorName, 0)
+}
+
+func doNetGame(g *Game, screen *ebiten.Image) {
+	buildGrid(screen)
+	doAppleScale()
+	doBodyFactor()
+
+	columns := layout.Columns(hudArea(), 4)
+	drawText(screen, tr("menu.multiplayer"), timerFont, columns[0], layout.Left, ParseHexColor("#749e35"))
+
+	drawGridPiece(screen, netClient.State.Apple.X, netClient.State.Apple.Y, ParseHexColor(nomColor), "apple", 0)
+
+	names := map[int]string{}
+	for _, player := range netClient.Players {
+		names[player.Snake] = player.Name
+	}
+
+	hud := ""
+	for _, snake := range netClient.Snakes() {
+		colorName := netColors[snake.ID%len(netColors)]
+		status := num(snake.Score)
+		if snake.Alive && len(snake.Body) > 0 {
+			drawNetSnake(screen, snake, colorName)
+			text.Draw(screen, names[snake.ID], timerFont, (snake.Body[0].X*gridCellWidth)+borderLeft, (snake.Body[0].Y*gridCellHeight)+borderTop-2, color.White)
+		} else {
+			status = tr("net.out")
+		}
+		hud += names[snake.ID] + ": " + status + "   "
+	}
+	drawText(screen, hud, timerFont, columns[1].Union(columns[2]), layout.Left, color.White)
+	drawText(screen, tr("hud.speed", num(netClient.State.Speed)), timerFont, columns[3], layout.Right, color.White)
+
+	if netClient.Phase == netplay.PhaseEnded {
+		drawBlackOverlay(screen)
+		result := tr("net.nobody")
+		if netClient.Winner == netClient.SnakeID() {
+			result = tr("net.you_win")
+		} else if netClient.Winner >= 0 {
+			result = tr("net.wins", names[netClient.Winner])
+		}
+		drawText(screen, result+"\n\n"+tr("net.ended_keys"), baseFont, screenArea(), layout.Center, color.White)
+	}
+}
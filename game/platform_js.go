@@ -0,0 +1,7 @@
+package game
+
+const (
+	canQuit       = false // a browser tab can't close itself
+	canWriteFiles = false
+	canNetplay    = false // no sockets in a browser, only HTTP
+)
@@ -0,0 +1,9 @@
+//go:build !js
+
+package game
+
+const (
+	canQuit       = true
+	canWriteFiles = true
+	canNetplay    = true
+)
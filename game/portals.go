@@ -0,0 +1,79 @@
+package game
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+var (
+	portalPhase = 0.0
+	// Both ends of a pair share their digit's color, so pairs are told apart
+	portalColors = []string{"#29b6f6", "#ab47bc", "#ffee58", "#ff7043", "#26a69a", "#ec407a", "#9ccc65", "#5c6bc0", "#ffa726", "#eeeeee"}
+)
+
+// portalPair returns the other end of the portal at the given cell
+func portalPair(ix int, iy int) (gridCell, bool) {
+	lvl := activeLevel()
+	if lvl == nil {
+		return gridCell{}, false
+	}
+	pair, ok := lvl.portals[gridCell{ix, iy}]
+	return pair, ok
+}
+
+// doPortal sends the head out of the paired portal if it just moved onto one.
+// The direction is kept, so the next move carries the snake away from the exit.
+func doPortal() bool {
+	pair, ok := portalPair(snakePlayer.xPos, snakePlayer.yPos)
+	if !ok {
+		return false
+	}
+	snakePlayer.xPos = pair.xPos
+	snakePlayer.yPos = pair.yPos
+	return true
+}
+
+// portalColor is the color of the portal at cell, picked by its digit
+func portalColor(cell gridCell) string {
+	return portalColors[activeLevel().digits[cell]%len(portalColors)]
+}
+
+// drawPortals draws each portal as a pulsing ring with a spinning spark
+func drawPortals(screen *ebiten.Image) {
+	lvl := activeLevel()
+	if lvl == nil {
+		return
+	}
+	if !gamePaused() {
+		portalPhase += .08
+	}
+
+	maxRadius := float64(gridCellWidth+gridCellHeight) / 5
+	for cell := range lvl.portals {
+		clr := ParseHexColor(portalColor(cell))
+		centerX := float64(cell.xPos*gridCellWidth) + float64(gridCellWidth)/2 + float64(borderLeft)
+		centerY := float64(cell.yPos*gridCellHeight) + float64(gridCellHeight)/2 + float64(borderTop)
+
+		radius := maxRadius * (.8 + .2*math.Sin(portalPhase))
+		ebitenutil.DrawCircle(screen, centerX, centerY, radius, clr)
+		ebitenutil.DrawCircle(screen, centerX, centerY, radius*.6, ParseHexColor(gridAltColor))
+
+		sparkX := centerX + math.Cos(portalPhase*2)*radius*.8
+		sparkY := centerY + math.Sin(portalPhase*2)*radius*.8
+		ebitenutil.DrawCircle(screen, sparkX, sparkY, radius*.2, clr)
+	}
+}
+
+// drawWarpMarker draws a small ring under a body piece that came out of a
+// portal, so the gap between it and the piece in front reads as a jump
+func drawWarpMarker(screen *ebiten.Image, ix int, iy int) {
+	if _, ok := portalPair(ix, iy); !ok {
+		return
+	}
+	centerX := float64(ix*gridCellWidth) + float64(gridCellWidth)/2 + float64(borderLeft)
+	centerY := float64(iy*gridCellHeight) + float64(gridCellHeight)/2 + float64(borderTop)
+	radius := float64(gridCellWidth+gridCellHeight) / 4
+	ebitenutil.DrawCircle(screen, centerX, centerY, radius, ParseHexColorAlpha(portalColor(gridCell{ix, iy}), 0x88))
+}
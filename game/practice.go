@@ -0,0 +1,114 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+const (
+	practiceHitPenalty  = 3  // body pieces lost on each hit
+	practiceMinBody     = 3  // never shrink below the starting snake
+	practiceFlashFrames = 90 // flashing, and safe from more hits, for this long
+	practiceMaxSpeed    = 10
+)
+
+var (
+	practiceFlash  = 0
+	showGridCoords = false
+)
+
+func resetPractice() {
+	practiceFlash = 0
+	if GameState == "game_practice" {
+		setPracticeSpeed(1)
+	}
+}
+
+// setPracticeSpeed maps the speed shown on screen onto the clock speed
+// the same way the normal ramp does, two frames faster per level
+func setPracticeSpeed(speed int) {
+	if speed < 1 {
+		speed = 1
+	}
+	if speed > practiceMaxSpeed {
+		speed = practiceMaxSpeed
+	}
+	runRamp.Shown = speed
+	runRamp.Speed = 22 - (speed * 2)
+}
+
+// handlePracticeKeys handles the speed and coordinate keys that only exist in practice
+func handlePracticeKeys() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEqual) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadAdd) {
+		setPracticeSpeed(runRamp.Shown + 1)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyMinus) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadSubtract) {
+		setPracticeSpeed(runRamp.Shown - 1)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyG) {
+		showGridCoords = !showGridCoords
+	}
+}
+
+// practiceHit stands in for game over in practice mode. The snake loses a
+// few pieces and flashes for a moment, and is safe while it flashes.
+func practiceHit() {
+	// Bounce off the walls by coming out the other side
+	if snakePlayer.xPos < 0 {
+		snakePlayer.xPos = gridWidth - 1
+	} else if snakePlayer.xPos >= gridWidth {
+		snakePlayer.xPos = 0
+	}
+	if snakePlayer.yPos < 0 {
+		snakePlayer.yPos = gridHeight - 1
+	} else if snakePlayer.yPos >= gridHeight {
+		snakePlayer.yPos = 0
+	}
+
+	if practiceFlash > 0 {
+		return
+	}
+	practiceFlash = practiceFlashFrames
+
+	// Drop pieces off the tail
+	newLength := len(snakePlayer.snakeBody) - practiceHitPenalty
+	if newLength < practiceMinBody {
+		newLength = practiceMinBody
+	}
+	keptBody := []snakeBody{}
+	for _, piece := range snakePlayer.snakeBody {
+		if piece.segment < newLength {
+			keptBody = append(keptBody, piece)
+		}
+	}
+	snakePlayer.snakeBody = keptBody
+	if len(snakePath) > newLength+1 {
+		snakePath = snakePath[:newLength+1]
+	}
+}
+
+// practiceSnakeHidden blinks the snake while it is flashing
+func practiceSnakeHidden() bool {
+	if practiceFlash <= 0 {
+		return false
+	}
+	if !gamePaused() {
+		practiceFlash--
+	}
+	return (practiceFlash/6)%2 == 0
+}
+
+// drawGridCoords labels the head and the apple with their grid coordinates
+func drawGridCoords(screen *ebiten.Image) {
+	label := func(ix int, iy int, clr color.Color) {
+		text.Draw(screen, fmt.Sprintf("%d,%d", ix, iy), timerFont, (ix*gridCellWidth)+borderLeft, (iy*gridCellHeight)+borderTop-2, clr)
+	}
+	label(snakePlayer.xPos, snakePlayer.yPos, color.White)
+	if nomActive {
+		label(currentNom.xPos, currentNom.yPos, ParseHexColor(nomColor))
+	}
+}
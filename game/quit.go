@@ -0,0 +1,24 @@
+package game
+
+import "errors"
+
+// ErrQuit is returned from Update when the player quits, RunGame passes it on
+var ErrQuit = errors.New("quit")
+
+// quitRequested is set by menus, whose items can't return errors, and
+// picked up by the next Update
+var quitRequested = false
+
+func requestQuit() {
+	quitRequested = true
+}
+
+// quitGame ends the game where there is somewhere to go back to. A page in
+// the browser has nowhere, so it goes back to the title screen instead.
+func quitGame() error {
+	if canQuit {
+		return ErrQuit
+	}
+	returnToTitle()
+	return nil
+}
@@ -0,0 +1,64 @@
+package game
+
+import (
+	"github.com/brantleyr/go-snake/replay"
+	"github.com/brantleyr/go-snake/rules"
+)
+
+var (
+	currentRun  *replay.Replay
+	runFinished bool
+)
+
+// replayMode is the name a game state goes by in replays and score tables
+func replayMode() string {
+	switch GameState {
+	case "game":
+		return activeDifficulty().mode()
+	case "game_shrink":
+		return "shrink"
+	case "game_level":
+		return "level"
+	case "game_practice":
+		return "practice"
+	case "game_daily":
+		return "daily"
+	}
+	return GameState
+}
+
+// startRun begins recording a run, called once the seed is picked
+func startRun() {
+	// Runs on a difficulty's curve can be played back, the other modes have more to them
+	var curve rules.Curve
+	if GameState == "game" {
+		curve = activeDifficulty().Curve
+	}
+	currentRun = replay.New(replayMode(), curve, runSeed)
+	runFinished = false
+	runFrames = nil
+	gifStatus = ""
+	runRamp.Clock.Reset()
+}
+
+// recordMove notes the direction the snake just moved in. It's always up,
+// down, left or right, turning controls are worked out before the move.
+func recordMove() {
+	if currentRun != nil {
+		currentRun.Record(snakePlayer.direction)
+	}
+}
+
+// finishRun files the score locally and with the leaderboard. The snake can
+// crash into more than one thing on its last move, so only the first call counts.
+func finishRun() {
+	if currentRun == nil || runFinished || GameState == "game_practice" {
+		return
+	}
+	runFinished = true
+	currentRun.Score = currScore
+	currentRun.Seconds = runRamp.Clock.Seconds()
+
+	recordHighScore(currentRun.Mode, currScore, runRamp.Clock.Millis())
+	submitScore(currentRun)
+}
@@ -0,0 +1,283 @@
+package game
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"github.com/brantleyr/go-snake/layout"
+)
+
+// Snake colors in the order the settings cycle through them, speed follows the speed
+var snakeColorChoices = []string{"speed", "green", "orange", "red"}
+
+// nextModes is the mode M on the game over screen changes to, anything
+// else goes back round to New Game
+var nextModes = map[string]string{
+	"game":        "game_shrink",
+	"game_shrink": "game_level",
+	"game_level":  "game_practice",
+}
+
+// runMenu is the menu shown while paused, or on the game over screen
+func runMenu(over bool) *menu {
+	items := []menuItem{
+		{label: tr("menu.resume"), action: popScene},
+		{label: tr("menu.restart"), action: func() {
+			popScene()
+			restartRun()
+		}},
+	}
+	if over {
+		items = []menuItem{{label: tr("menu.new_game"), action: func() {
+			popScene()
+			restartRun()
+		}}}
+	}
+	items = append(items,
+		menuItem{label: tr("menu.settings"), action: func() { pushScene(&settingsScene{}) }},
+		menuItem{label: tr("menu.quit_title"), action: func() { pushScene(&confirmScene{}) }},
+	)
+	if canQuit {
+		items = append(items, menuItem{label: tr("menu.quit_game"), action: func() { pushScene(&confirmScene{quit: true}) }})
+	}
+	return newMenu(items...)
+}
+
+// openRunMenu pauses the game with the first entry selected
+func openRunMenu() {
+	pushScene(&pauseScene{})
+}
+
+// restartRun starts the mode over from the beginning
+func restartRun() {
+	GameOverSndPlaying = false
+	currScore = 0
+	snakeHeading = ""
+
+	seedRun()
+	setupInitialSnake()
+	resetHazards()
+	resetSpeed()
+	resetShrink()
+	resetPractice()
+	startDailySpeed()
+}
+
+// returnToTitle leaves the run, whatever state it's in
+func returnToTitle() {
+	showScreen("title")
+}
+
+// runMenuArea is where the pause and game over menus go, just under the
+// middle of the screen. Settings and the confirmations go in their place.
+func runMenuArea() image.Rectangle {
+	return layout.Inset(belowMiddle(), textPadding)
+}
+
+// pauseScene holds the game while its menu is open
+type pauseScene struct {
+	menu *menu
+}
+
+func (s *pauseScene) Enter() {
+	s.menu = runMenu(false)
+}
+
+func (s *pauseScene) Exit() {}
+
+func (s *pauseScene) Update(g *Game) error {
+	if s.menu.update() {
+		popScene()
+	}
+	return nil
+}
+
+func (s *pauseScene) Draw(g *Game, screen *ebiten.Image) {
+	drawBlackOverlay(screen)
+	drawText(screen, tr("pause.heading"), baseFont, aboveMiddle(), layout.Bottom, color.White)
+	if sceneOnTop(s) {
+		s.menu.draw(screen, baseFont, runMenuArea(), 40)
+	}
+}
+
+// gameOverScene is opened by snakeCrashed over the board the snake died on
+type gameOverScene struct {
+	menu *menu
+}
+
+func (s *gameOverScene) Enter() {
+	s.menu = runMenu(true)
+	if !muted {
+		GameOverSndPlaying = true
+		gameOverSnd.Seek(0)
+		gameOverSnd.Play()
+	}
+}
+
+func (s *gameOverScene) Exit() {}
+
+func (s *gameOverScene) Update(g *Game) error {
+	if s.menu.update() && canQuit {
+		pushScene(&confirmScene{quit: true})
+		return nil
+	}
+
+	// Mode, level and GIF keys
+	if inpututil.IsKeyJustPressed(ebiten.KeyM) {
+		next, ok := nextModes[GameState]
+		if !ok {
+			next = "game"
+		}
+		showScreen(next)
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyL) && GameState == "game_level" {
+		nextLevel()
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyG) {
+		// Shift saves the whole run, otherwise just the last few seconds
+		exportGIF(ebiten.IsKeyPressed(ebiten.KeyShift))
+	}
+	return nil
+}
+
+func (s *gameOverScene) Draw(g *Game, screen *ebiten.Image) {
+	drawBlackOverlay(screen)
+	gameOverText := tr("game_over.heading")
+	hotKeys := tr("game_over.keys")
+	if GameState == "game_shrink" {
+		// Survival is scored on time, not apples
+		seconds := runRamp.Clock.Seconds()
+		gameOverText += "\n" + trn("game_over.survived", seconds, num(seconds))
+	} else if GameState == "game_daily" {
+		gameOverText += "\n" + tr("game_over.best_today", num(dailyHistory[dailyToday.date].Score))
+	} else if GameState == "game_level" {
+		hotKeys = tr("game_over.keys_level")
+	}
+	drawn := drawText(screen, gameOverText, baseFont, aboveMiddle(), layout.Bottom, color.White)
+	if sceneOnTop(s) {
+		s.menu.draw(screen, baseFont, runMenuArea(), 40)
+	}
+	scoreStatus := leaderboardStatus
+	if newHighScore {
+		scoreStatus = tr("game_over.high_score") + "   " + scoreStatus
+	}
+	drawn = drawText(screen, scoreStatus, timerFont, above(drawn), layout.Bottom, ParseHexColor("#749e35"))
+	if gameOver() {
+		// Not once New Game has the next snake out while this fades
+		drawSnakeDead(screen, above(drawn))
+	}
+	drawText(screen, hotKeys+"\n"+gifStatus, timerFont, screenArea(), layout.Bottom, ParseHexColor("#8c8c8c"))
+}
+
+// settingsScene opens over the pause or game over menu
+type settingsScene struct {
+	menu *menu
+}
+
+func (s *settingsScene) Enter() {
+	s.menu = newMenu(settingsItems()...)
+}
+
+func (s *settingsScene) Exit() {}
+
+func settingsItems() []menuItem {
+	sound := tr("settings.on")
+	if muted {
+		sound = tr("settings.off")
+	}
+	toggleSound := func() {
+		muted = !muted
+		if muted {
+			gameOverSnd.Pause()
+		}
+		saveSettings()
+	}
+	nextColor := func(step int) {
+		next := (snakeColorIndex() + len(snakeColorChoices) + step) % len(snakeColorChoices)
+		manualColorOverride = next != 0
+		if manualColorOverride {
+			manualColor = snakeColorChoices[next]
+		}
+		saveSettings()
+	}
+	nextControls := func(step int) {
+		controls = controlChoices[(controlsIndex()+len(controlChoices)+step)%len(controlChoices)]
+		saveSettings()
+	}
+	return []menuItem{
+		{label: tr("settings.sound", sound), action: toggleSound, adjust: func(int) { toggleSound() }},
+		{label: tr("settings.color", tr("color."+snakeColorChoices[snakeColorIndex()])), action: func() { nextColor(1) }, adjust: nextColor},
+		{label: tr("settings.controls", tr("controls."+controls)), action: func() { nextControls(1) }, adjust: nextControls},
+		{label: tr("settings.language", lang.Name), action: func() { nextLanguage(1) }, adjust: nextLanguage},
+		{label: tr("settings.accessibility"), action: func() { pushScene(&accessibilityScene{}) }},
+		{label: tr("menu.back"), action: popScene},
+	}
+}
+
+func (s *settingsScene) Update(g *Game) error {
+	if s.menu.update() {
+		popScene()
+	}
+	// The labels show the values, so they change with them
+	s.menu.setItems(settingsItems())
+	return nil
+}
+
+func (s *settingsScene) Draw(g *Game, screen *ebiten.Image) {
+	// Accessibility opens in the settings' place
+	if !sceneOnTop(s) {
+		return
+	}
+	drawn := s.menu.draw(screen, baseFont, runMenuArea(), 40)
+	drawText(screen, tr("settings.keys"), timerFont, under(drawn), layout.Top, ParseHexColor("#8c8c8c"))
+}
+
+func snakeColorIndex() int {
+	if !manualColorOverride {
+		return 0
+	}
+	for idx, choice := range snakeColorChoices {
+		if choice == manualColor {
+			return idx
+		}
+	}
+	return 0
+}
+
+// confirmScene asks before quitting to the title, or quitting the game
+type confirmScene struct {
+	quit bool
+	menu *menu
+}
+
+func (s *confirmScene) Enter() {
+	yes := returnToTitle
+	if s.quit {
+		yes = requestQuit
+	}
+	s.menu = newMenu(
+		menuItem{label: tr("menu.no"), action: popScene},
+		menuItem{label: tr("menu.yes"), action: yes},
+	)
+}
+
+func (s *confirmScene) Exit() {}
+
+func (s *confirmScene) Update(g *Game) error {
+	if inpututil.IsKeyJustPressed(ebiten.KeyY) {
+		s.menu.choose(1)
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyN) || s.menu.update() {
+		popScene()
+	}
+	return nil
+}
+
+func (s *confirmScene) Draw(g *Game, screen *ebiten.Image) {
+	question := tr("confirm.title")
+	if s.quit {
+		question = tr("confirm.quit")
+	}
+	drawn := drawText(screen, question, baseFont, runMenuArea(), layout.Top, color.White)
+	s.menu.draw(screen, baseFont, under(drawn), 40)
+}
@@ -0,0 +1,194 @@
+package game
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// sceneFade is how much opacity a scene gains or loses each update, so a
+// fade takes a sixth of a second
+const sceneFade = .1
+
+// Scene is one screen of the game. Scenes sit on a stack: the top one gets
+// the keys, and everything on the stack is drawn bottom up, so the pause
+// menu and dialogs draw over the game they belong to.
+type Scene interface {
+	Enter()
+	Exit()
+	Update(g *Game) error
+	Draw(g *Game, screen *ebiten.Image)
+}
+
+type sceneEntry struct {
+	scene   Scene
+	opacity float64
+	closing bool // popped and fading out, it's already had its Exit
+}
+
+var (
+	scenes     []*sceneEntry
+	sceneLayer *ebiten.Image // scenes that are fading are drawn here first
+
+	// screens makes the scene for each GameState name, see showScreen
+	screens map[string]func() Scene
+)
+
+func init() {
+	screens = map[string]func() Scene{
+		"intro":         func() Scene { return &introScene{} },
+		"title":         func() Scene { return &titleScene{} },
+		"game":          func() Scene { return &gameScene{mode: "game"} },
+		"game_shrink":   func() Scene { return &gameScene{mode: "game_shrink"} },
+		"game_level":    func() Scene { return &gameScene{mode: "game_level"} },
+		"game_practice": func() Scene { return &gameScene{mode: "game_practice"} },
+		"game_daily":    func() Scene { return &gameScene{mode: "game_daily"} },
+		"daily":         func() Scene { return &screenScene{"daily", handleDailyKeys, doDaily} },
+		"leaderboard":   func() Scene { return &screenScene{"leaderboard", handleLeaderboardKeys, doLeaderboard} },
+		"difficulty":    func() Scene { return &screenScene{"difficulty", handleDifficultyKeys, doDifficulty} },
+		"net_join":      func() Scene { return &screenScene{"net_join", handleNetJoinKeys, doNetJoin} },
+		"net_lobby":     func() Scene { return &screenScene{"net_lobby", handleNetLobbyKeys, doNetLobby} },
+		"net_game":      func() Scene { return &screenScene{"net_game", handleNetGameKeys, doNetGame} },
+	}
+}
+
+// ShowScreen swaps whatever is open for the named screen, main uses it to
+// start on the intro
+func ShowScreen(name string) {
+	showScreen(name)
+}
+
+func showScreen(name string) {
+	makeScene, ok := screens[name]
+	if !ok {
+		makeScene = screens["title"]
+	}
+	scene := makeScene()
+	switchScene(scene)
+	// Games wait behind the start text until the player's ready
+	if _, ok := scene.(*gameScene); ok {
+		pushScene(&startScene{})
+	}
+}
+
+// switchScene closes every scene and opens the new one. The old ones go
+// straight away, what they draw may not be there any more, and the new
+// one fades in from black like the intro.
+func switchScene(scene Scene) {
+	for idx := len(scenes) - 1; idx >= 0; idx-- {
+		closeScene(scenes[idx])
+	}
+	scenes = nil
+	pushScene(scene)
+}
+
+// pushScene opens a scene over the ones already open
+func pushScene(scene Scene) {
+	scene.Enter()
+	scenes = append(scenes, &sceneEntry{scene: scene})
+}
+
+// popScene closes the top scene, the one under it gets the keys again
+func popScene() {
+	if entry := topScene(); entry != nil {
+		closeScene(entry)
+	}
+}
+
+func closeScene(entry *sceneEntry) {
+	if entry.closing {
+		return
+	}
+	entry.closing = true
+	entry.scene.Exit()
+}
+
+// topScene is the scene with the keys, scenes fading out don't count
+func topScene() *sceneEntry {
+	for idx := len(scenes) - 1; idx >= 0; idx-- {
+		if !scenes[idx].closing {
+			return scenes[idx]
+		}
+	}
+	return nil
+}
+
+// sceneOpen reports whether any open scene matches is, one fading out doesn't count
+func sceneOpen(is func(Scene) bool) bool {
+	for _, entry := range scenes {
+		if !entry.closing && is(entry.scene) {
+			return true
+		}
+	}
+	return false
+}
+
+// sceneOnTop reports whether nothing is open over the scene
+func sceneOnTop(scene Scene) bool {
+	top := topScene()
+	return top != nil && top.scene == scene
+}
+
+// updateScenes moves the fades along and lets the top scene handle the keys
+func updateScenes(g *Game) error {
+	open := scenes[:0]
+	for _, entry := range scenes {
+		if entry.closing {
+			entry.opacity -= sceneFade
+			if entry.opacity <= 0 {
+				continue
+			}
+		} else if entry.opacity < 1 {
+			entry.opacity += sceneFade
+			if entry.opacity > 1 {
+				entry.opacity = 1
+			}
+		}
+		open = append(open, entry)
+	}
+	scenes = open
+
+	if top := topScene(); top != nil {
+		return top.scene.Update(g)
+	}
+	return nil
+}
+
+// drawScenes draws the stack bottom up
+func drawScenes(g *Game, screen *ebiten.Image) {
+	for _, entry := range scenes {
+		if entry.opacity >= 1 {
+			entry.scene.Draw(g, screen)
+			continue
+		}
+
+		if sceneLayer == nil || sceneLayer.Bounds().Dx() != ScreenWidth || sceneLayer.Bounds().Dy() != ScreenHeight {
+			sceneLayer = ebiten.NewImage(ScreenWidth, ScreenHeight)
+		}
+		sceneLayer.Clear()
+		entry.scene.Draw(g, sceneLayer)
+		layerOp := &ebiten.DrawImageOptions{}
+		layerOp.ColorM.Scale(1, 1, 1, entry.opacity)
+		screen.DrawImage(sceneLayer, layerOp)
+	}
+}
+
+// screenScene is a full screen that's just a key handler and a draw function
+type screenScene struct {
+	name string
+	keys func()
+	draw func(g *Game, screen *ebiten.Image)
+}
+
+func (s *screenScene) Enter() {
+	GameState = s.name
+}
+
+func (s *screenScene) Exit() {}
+
+func (s *screenScene) Update(g *Game) error {
+	s.keys()
+	return nil
+}
+
+func (s *screenScene) Draw(g *Game, screen *ebiten.Image) {
+	s.draw(g, screen)
+}
@@ -0,0 +1,211 @@
+package game
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/brantleyr/go-snake/layout"
+	"github.com/brantleyr/go-snake/replay"
+	"github.com/brantleyr/go-snake/rules"
+)
+
+const (
+	screenshotDir        = "screenshots"
+	screenshotNoteFrames = 120 // how long "Saved ..." stays up
+	pngSignature         = "\x89PNG\r\n\x1a\n"
+	pngIHDREnd           = len(pngSignature) + 8 + 13 + 4 // IHDR is always first and 13 bytes long
+)
+
+// The text chunks a screenshot carries, in the order they're written
+var screenshotKeys = []string{"Software", "Mode", "Score", "Seed", "Tick", "Speed", "Millis", "Replay"}
+
+var (
+	screenshotWanted bool
+	screenshotNote   string
+	screenshotFrames int
+)
+
+// screenshotMeta is what goes into a screenshot's text chunks. The replay
+// up to that tick goes too, so the game can be put back exactly as it was.
+func screenshotMeta() map[string]string {
+	meta := map[string]string{
+		"Software": GameTitle,
+		"Mode":     replayMode(),
+		"Score":    strconv.Itoa(currScore),
+		"Seed":     strconv.FormatInt(runSeed, 10),
+		"Tick":     "0",
+		"Speed":    strconv.Itoa(runRamp.Shown),
+		"Millis":   strconv.Itoa(runRamp.Clock.Millis()),
+	}
+	if currentRun != nil && (gameStarted() || gameOver()) {
+		meta["Tick"] = strconv.Itoa(len(currentRun.Moves))
+		if data, err := json.Marshal(currentRun); err == nil {
+			meta["Replay"] = string(data)
+		}
+	}
+	return meta
+}
+
+// takeScreenshot saves what was just drawn, called at the end of Draw
+func takeScreenshot(screen *ebiten.Image) {
+	screenshotWanted = false
+	screenshotFrames = screenshotNoteFrames
+	if !canWriteFiles {
+		screenshotNote = tr("screenshot.unavailable")
+		return
+	}
+
+	img := image.NewRGBA(screen.Bounds())
+	screen.ReadPixels(img.Pix)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		screenshotNote = tr("screenshot.error", err.Error())
+		return
+	}
+	data, err := addPNGText(buf.Bytes(), screenshotMeta())
+	if err != nil {
+		screenshotNote = tr("screenshot.error", err.Error())
+		return
+	}
+
+	path := filepath.Join(screenshotDir, "go-snake-"+time.Now().Format("20060102-150405.000")+".png")
+	if err := os.MkdirAll(screenshotDir, 0o755); err != nil {
+		screenshotNote = tr("screenshot.error", err.Error())
+		return
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		screenshotNote = tr("screenshot.error", err.Error())
+		return
+	}
+	screenshotNote = tr("saved", path)
+}
+
+// drawScreenshotNote says where the last screenshot went, for a couple of seconds
+func drawScreenshotNote(screen *ebiten.Image) {
+	if screenshotFrames == 0 {
+		return
+	}
+	screenshotFrames--
+	drawText(screen, screenshotNote, timerFont, screenArea(), layout.BottomLeft, ParseHexColor("#8c8c8c"))
+}
+
+// addPNGText puts tEXt chunks in right after the header, image/png has no
+// way of writing them itself
+func addPNGText(data []byte, meta map[string]string) ([]byte, error) {
+	if len(data) < pngIHDREnd || string(data[12:16]) != "IHDR" {
+		return nil, errors.New("not a PNG")
+	}
+	var chunks bytes.Buffer
+	for _, key := range screenshotKeys {
+		if value, ok := meta[key]; ok {
+			writePNGChunk(&chunks, "tEXt", []byte(key+"\x00"+value))
+		}
+	}
+	out := append([]byte{}, data[:pngIHDREnd]...)
+	out = append(out, chunks.Bytes()...)
+	return append(out, data[pngIHDREnd:]...), nil
+}
+
+func writePNGChunk(buf *bytes.Buffer, kind string, body []byte) {
+	crc := crc32.NewIEEE()
+	crc.Write([]byte(kind))
+	crc.Write(body)
+	binary.Write(buf, binary.BigEndian, uint32(len(body)))
+	buf.WriteString(kind)
+	buf.Write(body)
+	binary.Write(buf, binary.BigEndian, crc.Sum32())
+}
+
+// readPNGText pulls the tEXt chunks back out of a PNG
+func readPNGText(data []byte) (map[string]string, error) {
+	if !bytes.HasPrefix(data, []byte(pngSignature)) {
+		return nil, errors.New("not a PNG")
+	}
+	meta := map[string]string{}
+	for pos := len(pngSignature); pos+8 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		kind := string(data[pos+4 : pos+8])
+		if pos+12+length > len(data) {
+			return nil, errors.New("the PNG is cut short")
+		}
+		if kind == "tEXt" {
+			body := data[pos+8 : pos+8+length]
+			if idx := bytes.IndexByte(body, 0); idx > 0 {
+				meta[string(body[:idx])] = string(body[idx+1:])
+			}
+		}
+		if kind == "IEND" {
+			break
+		}
+		pos += 12 + length
+	}
+	return meta, nil
+}
+
+// LoadScreenshot puts the game back the way it was when a screenshot was
+// taken, paused, by playing its replay up to the screenshot's tick. Only
+// runs on a difficulty can be played back like that.
+func LoadScreenshot(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	meta, err := readPNGText(data)
+	if err != nil {
+		return err
+	}
+	if meta["Replay"] == "" {
+		return errors.New("the screenshot has no replay in it")
+	}
+	run := &replay.Replay{}
+	if err := json.Unmarshal([]byte(meta["Replay"]), run); err != nil {
+		return err
+	}
+	if !run.Verifiable() {
+		return fmt.Errorf("%s runs can't be played back, only runs on a difficulty", run.Mode)
+	}
+	tick, err := strconv.Atoi(meta["Tick"])
+	if err != nil {
+		return fmt.Errorf("bad tick %q", meta["Tick"])
+	}
+	if tick > len(run.Moves) {
+		return fmt.Errorf("tick %d isn't in the replay, it only has %d moves", tick, len(run.Moves))
+	}
+
+	arena := rules.NewArena(rules.DefaultWidth, rules.DefaultHeight, run.Seed, 1)
+	arena.SetCurve(run.Curve)
+	for move := 0; move < tick && !arena.Over(); move++ {
+		arena.SetDirection(0, run.Direction(move))
+		arena.Step()
+	}
+
+	playReplayDifficulty(run)
+	// Straight into the run, there's no start text to get past
+	switchScene(screens["game"]())
+	applyFrame(arenaFrame(arena, 0))
+	// The arena's clock ran as the game's would have, speed ups still to come included
+	runRamp.Restore(&arena.Ramp)
+
+	// Apples carry on from where the simulation left off
+	gameRand = arena.Rand()
+	runSeed = run.Seed
+	run.Moves = run.Moves[:tick]
+	currentRun = run
+	runFinished = false
+
+	openRunMenu()
+	return nil
+}
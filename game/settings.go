@@ -0,0 +1,105 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+
+	"github.com/brantleyr/go-snake/storage"
+)
+
+const settingsFile = "settings.json"
+
+// settings are the choices the player makes that should still be there next time
+type settings struct {
+	Muted bool   `json:"muted"`
+	Color string `json:"color,omitempty"` // snake color picked with C, empty follows the speed
+
+	Language string `json:"language,omitempty"` // empty goes with the system's
+	Controls string `json:"controls,omitempty"` // empty is absolute
+
+	Palette       string `json:"palette,omitempty"` // empty is the standard green, orange and red
+	HighContrast  bool   `json:"high_contrast,omitempty"`
+	ReducedMotion bool   `json:"reduced_motion,omitempty"`
+	GameSpeed     int    `json:"game_speed,omitempty"` // percent, empty is full speed
+
+	Difficulty string      `json:"difficulty,omitempty"` // what New Game plays
+	Custom     *difficulty `json:"custom,omitempty"`
+}
+
+// store is where settings, high scores and daily results are saved:
+// files on desktops, localStorage in a browser
+var store = storage.Default()
+
+func loadSettings() {
+	data, err := store.Load(settingsFile)
+	if errors.Is(err, storage.ErrNotFound) {
+		return
+	}
+	if err != nil {
+		log.Println("settings:", err)
+		return
+	}
+	var saved settings
+	if err := json.Unmarshal(data, &saved); err != nil {
+		log.Println("settings:", err)
+		return
+	}
+	muted = saved.Muted
+	if saved.Color != "" {
+		manualColorOverride = true
+		manualColor = saved.Color
+	}
+	if saved.Language != "" && languageByCode(saved.Language) != nil {
+		lang = languageByCode(saved.Language)
+	}
+	for _, choice := range controlChoices {
+		if choice == saved.Controls {
+			controls = choice
+		}
+	}
+	for _, choice := range paletteChoices {
+		if choice == saved.Palette {
+			snakePalette = choice
+		}
+	}
+	highContrast = saved.HighContrast
+	reducedMotion = saved.ReducedMotion
+	if saved.GameSpeed >= minGameSpeed && saved.GameSpeed <= 100 {
+		gameSpeed = saved.GameSpeed
+	}
+	if saved.Custom != nil && saved.Custom.check() == nil {
+		custom := difficultyByID(customDifficulty)
+		*custom = *saved.Custom
+		custom.ID = customDifficulty
+	}
+	if difficultyByID(saved.Difficulty).ID == saved.Difficulty {
+		selectedDifficulty = saved.Difficulty
+	}
+}
+
+func saveSettings() {
+	current := settings{Muted: muted, Language: lang.Lang, Difficulty: selectedDifficulty, Custom: difficultyByID(customDifficulty)}
+	if manualColorOverride {
+		current.Color = manualColor
+	}
+	if controls != controlChoices[0] {
+		current.Controls = controls
+	}
+	if snakePalette != standardPalette {
+		current.Palette = snakePalette
+	}
+	current.HighContrast = highContrast
+	current.ReducedMotion = reducedMotion
+	if gameSpeed != 100 {
+		current.GameSpeed = gameSpeed
+	}
+	data, err := json.MarshalIndent(current, "", "  ")
+	if err != nil {
+		log.Println("settings:", err)
+		return
+	}
+	if err := store.Save(settingsFile, data); err != nil {
+		log.Println("settings:", err)
+	}
+}
@@ -0,0 +1,99 @@
+package game
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	shrinkInterval     = 15 // seconds between each ring closing
+	shrinkWarnTime     = 3  // seconds of warning before a ring closes
+	shrinkMinLiveCells = 5  // the live area never gets narrower than this
+	shrinkWallColor    = "#5a3a12"
+	shrinkWarnColor    = "#ff9300"
+)
+
+var (
+	shrinkRing = 0 // number of rings that have closed in so far
+)
+
+// maxShrinkRing is the last ring that can close while still leaving
+// shrinkMinLiveCells of open grid in the smaller direction
+func maxShrinkRing() int {
+	smallest := gridWidth
+	if gridHeight < smallest {
+		smallest = gridHeight
+	}
+	return (smallest - shrinkMinLiveCells) / 2
+}
+
+// isClosedCell reports whether the cell has been swallowed by the arena
+func isClosedCell(ix int, iy int) bool {
+	if GameState != "game_shrink" {
+		return false
+	}
+	return isInRing(ix, iy, shrinkRing)
+}
+
+// isInRing reports whether the cell lies outside the live area left after
+// the given number of rings have closed
+func isInRing(ix int, iy int, ring int) bool {
+	return ix < ring || iy < ring || ix >= gridWidth-ring || iy >= gridHeight-ring
+}
+
+func resetShrink() {
+	shrinkRing = 0
+}
+
+// doShrink closes the next ring once enough time has elapsed and checks
+// whether the snake got caught in it
+func doShrink() {
+	if gameStarted() && !gamePaused() {
+		targetRing := runRamp.Clock.Seconds() / shrinkInterval
+		if targetRing > maxShrinkRing() {
+			targetRing = maxShrinkRing()
+		}
+		if targetRing > shrinkRing {
+			shrinkRing = targetRing
+
+			// Throw the apple back in if the arena ate it
+			if nomActive && isClosedCell(currentNom.xPos, currentNom.yPos) {
+				nomActive = false
+			}
+		}
+	}
+
+	// Head or any body piece inside the closed area ends the game
+	if gameStarted() {
+		caught := isClosedCell(snakePlayer.xPos, snakePlayer.yPos)
+		for idx := 0; idx < len(snakePlayer.snakeBody) && idx < len(snakePath); idx++ {
+			if isClosedCell(snakePath[idx].xPos, snakePath[idx].yPos) {
+				caught = true
+			}
+		}
+		if caught {
+			snakeCrashed()
+		}
+	}
+}
+
+// drawShrinkWalls draws the closed rings, and flashes the next ring shortly before it closes
+func drawShrinkWalls(screen *ebiten.Image) {
+	var warnColor color.Color
+	nextRing := shrinkRing + 1
+	secondsLeft := (nextRing * shrinkInterval) - runRamp.Clock.Seconds()
+	if nextRing <= maxShrinkRing() && secondsLeft <= shrinkWarnTime && runRamp.Clock.Seconds()%2 == 0 {
+		warnColor = ParseHexColorAlpha(shrinkWarnColor, 0x66)
+	}
+
+	for ix := 0; ix < gridWidth; ix++ {
+		for iy := 0; iy < gridHeight; iy++ {
+			if isInRing(ix, iy, shrinkRing) {
+				drawGridPiece(screen, ix, iy, ParseHexColor(shrinkWallColor), "rect", 0)
+			} else if warnColor != nil && isInRing(ix, iy, nextRing) {
+				drawGridPiece(screen, ix, iy, warnColor, "rect", 0)
+			}
+		}
+	}
+}
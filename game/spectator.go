@@ -0,0 +1,54 @@
+package game
+
+import (
+	"github.com/brantleyr/go-snake/spectate"
+)
+
+var (
+	spectators *spectate.Server
+)
+
+// StartSpectating serves the live game to browsers on addr, for example ":8080"
+func StartSpectating(addr string) (string, error) {
+	server, err := spectate.Listen(addr)
+	if err != nil {
+		return "", err
+	}
+	spectators = server
+	return server.Addr(), nil
+}
+
+// publishSpectatorFrame sends the state of the board to the spectators
+func publishSpectatorFrame() {
+	if spectators == nil {
+		return
+	}
+
+	status := "playing"
+	if gameOver() {
+		status = "over"
+	} else if !gameStarted() {
+		status = "waiting"
+	} else if gamePaused() {
+		status = "paused"
+	}
+
+	frame := spectate.Frame{
+		Mode:      modeLabel(),
+		Status:    status,
+		Width:     gridWidth,
+		Height:    gridHeight,
+		Snake:     []spectate.Point{{X: snakePlayer.xPos, Y: snakePlayer.yPos}},
+		Direction: snakePlayer.direction,
+		Score:     currScore,
+		Speed:     runRamp.Shown,
+		Elapsed:   runRamp.Clock.Seconds(),
+	}
+	for segment := 0; segment < len(snakePlayer.snakeBody) && segment < len(snakePath); segment++ {
+		frame.Snake = append(frame.Snake, spectate.Point{X: snakePath[segment].xPos, Y: snakePath[segment].yPos})
+	}
+	if nomActive {
+		frame.Apple = &spectate.Point{X: currentNom.xPos, Y: currentNom.yPos}
+	}
+	spectators.Publish(frame)
+}
@@ -0,0 +1,25 @@
+package game
+
+import "github.com/brantleyr/go-snake/rules"
+
+// runRamp is how fast the run is going, and its clock is the game clock.
+// stepGame ticks it, so the simulation owns it and everything its events change.
+var runRamp = rules.Ramp{Speed: 20, Shown: 1}
+
+// resetSpeed puts the snake back to the starting speed of the mode's difficulty
+func resetSpeed() {
+	runRamp.Reset(activeDifficulty().Curve)
+}
+
+// scheduleSpeedUp queues a speed up every few apples. Practice speed is set by hand.
+func scheduleSpeedUp() {
+	if GameState != "game_practice" {
+		runRamp.Ate(currScore)
+	}
+}
+
+// speedColor is the snake's color at the speed shown, by the difficulty's tiers
+func speedColor() (string, string) {
+	tier := activeDifficulty().Tier(runRamp.Shown)
+	return tier.Color, tier.Name
+}
@@ -0,0 +1,65 @@
+package game
+
+import (
+	"image"
+	"image/color"
+
+	"golang.org/x/image/font"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+
+	"github.com/brantleyr/go-snake/layout"
+)
+
+// textPadding keeps text off the edges of whatever area it's anchored in
+const textPadding = 10
+
+// drawText draws s against the anchor of the area and gives back where it
+// went, so the next thing can go under or next to it
+func drawText(screen *ebiten.Image, s string, face font.Face, area image.Rectangle, anchor layout.Anchor, clr color.Color) image.Rectangle {
+	lines := layout.Place(face, s, area, anchor, textPadding)
+	for _, line := range lines {
+		text.Draw(screen, line.Text, face, line.Dot.X, line.Dot.Y, clr)
+	}
+	return layout.Bounds(face, lines)
+}
+
+func screenArea() image.Rectangle {
+	return image.Rect(0, 0, ScreenWidth, ScreenHeight)
+}
+
+// hudArea is the bar above the board
+func hudArea() image.Rectangle {
+	return image.Rect(0, 0, ScreenWidth, borderTop)
+}
+
+// aboveMiddle and belowMiddle split the screen in two, overlays put their
+// heading in the top half and their menu in the bottom
+func aboveMiddle() image.Rectangle {
+	return image.Rect(0, 0, ScreenWidth, ScreenHeight/2)
+}
+
+func belowMiddle() image.Rectangle {
+	return image.Rect(0, ScreenHeight/2, ScreenWidth, ScreenHeight)
+}
+
+// under is the rest of the screen below something already drawn
+func under(drawn image.Rectangle) image.Rectangle {
+	return image.Rect(0, drawn.Max.Y, ScreenWidth, ScreenHeight)
+}
+
+// above is the part of the screen over something already drawn
+func above(drawn image.Rectangle) image.Rectangle {
+	return image.Rect(0, 0, ScreenWidth, drawn.Min.Y)
+}
+
+// drawHeading draws a screen's name at the top, under the HUD bar's height
+func drawHeading(screen *ebiten.Image, heading string) image.Rectangle {
+	return drawText(screen, heading, titleFont, image.Rect(0, borderTop, ScreenWidth, ScreenHeight), layout.Top, color.White)
+}
+
+// drawFooter draws the keys a screen takes along the bottom
+func drawFooter(screen *ebiten.Image, keys string) {
+	drawText(screen, keys, timerFont, screenArea(), layout.Bottom, color.White)
+}
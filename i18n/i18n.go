@@ -0,0 +1,169 @@
+// Package i18n holds the game's text in other languages. Each language is a
+// catalog loaded from a JSON file:
+//
+//	{
+//	  "lang": "es",
+//	  "name": "Español",
+//	  "plural": "one_other",
+//	  "number": {"decimal": ",", "group": "."},
+//	  "messages": {
+//	    "menu.new_game": "Nueva partida",
+//	    "game_over.survived": {"one": "Sobreviviste %s segundo", "other": "Sobreviviste %s segundos"}
+//	  }
+//	}
+//
+// Messages are fmt formats. A message with plural forms is an object keyed by
+// form, picked by the language's plural rule. Numbers are formatted by the
+// catalog and passed in as strings, so they get the language's separators.
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Plural rules, named for the forms they use
+const (
+	OneOther = "one_other" // English, Spanish and most of Europe: 1 is one, everything else other
+	Other    = "other"     // Japanese, Chinese and Korean don't change for numbers
+)
+
+// Catalog is every message in one language
+type Catalog struct {
+	Lang       string `json:"lang"`
+	Name       string `json:"name"` // in the language itself, for the settings
+	PluralRule string `json:"plural"`
+	Number     struct {
+		Decimal string `json:"decimal"`
+		Group   string `json:"group"`
+	} `json:"number"`
+	Messages map[string]message `json:"messages"`
+
+	// Fallback has the messages this one is missing, usually English
+	Fallback *Catalog `json:"-"`
+}
+
+// message is a plain format, or one for each plural form
+type message struct {
+	forms map[string]string
+}
+
+func (m *message) UnmarshalJSON(data []byte) error {
+	var plain string
+	if err := json.Unmarshal(data, &plain); err == nil {
+		m.forms = map[string]string{"other": plain}
+		return nil
+	}
+	if err := json.Unmarshal(data, &m.forms); err != nil {
+		return fmt.Errorf("a message is a string or an object of plural forms: %w", err)
+	}
+	if _, ok := m.forms["other"]; !ok {
+		return fmt.Errorf("plural forms need an \"other\"")
+	}
+	return nil
+}
+
+// Parse reads a catalog from JSON
+func Parse(data []byte) (*Catalog, error) {
+	c := &Catalog{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	if c.Lang == "" || c.Name == "" {
+		return nil, fmt.Errorf("needs a lang and a name")
+	}
+	switch c.PluralRule {
+	case "":
+		c.PluralRule = OneOther
+	case OneOther, Other:
+	default:
+		return nil, fmt.Errorf("unknown plural rule %q", c.PluralRule)
+	}
+	if c.Number.Decimal == "" {
+		c.Number.Decimal = "."
+	}
+	return c, nil
+}
+
+// lookup finds the message here or in the fallbacks
+func (c *Catalog) lookup(key string) (message, bool) {
+	for catalog := c; catalog != nil; catalog = catalog.Fallback {
+		if m, ok := catalog.Messages[key]; ok {
+			return m, true
+		}
+	}
+	return message{}, false
+}
+
+// Text is the message for key filled in with args. A message nobody has
+// comes out as its key, so it's easy to spot.
+func (c *Catalog) Text(key string, args ...interface{}) string {
+	m, ok := c.lookup(key)
+	if !ok {
+		return key
+	}
+	return format(m.forms["other"], args)
+}
+
+// Plural is Text with the form for n
+func (c *Catalog) Plural(key string, n int, args ...interface{}) string {
+	m, ok := c.lookup(key)
+	if !ok {
+		return key
+	}
+	form, ok := m.forms[c.form(n)]
+	if !ok {
+		form = m.forms["other"]
+	}
+	return format(form, args)
+}
+
+func (c *Catalog) form(n int) string {
+	if c.PluralRule == OneOther && (n == 1 || n == -1) {
+		return "one"
+	}
+	return "other"
+}
+
+func format(text string, args []interface{}) string {
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// Int formats a whole number with the language's grouping, 12,345 or 12.345
+func (c *Catalog) Int(n int) string {
+	digits := strconv.Itoa(n)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	if c.Number.Group == "" || len(digits) <= 3 {
+		return sign + digits
+	}
+	groups := []string{}
+	for len(digits) > 3 {
+		groups = append([]string{digits[len(digits)-3:]}, groups...)
+		digits = digits[:len(digits)-3]
+	}
+	groups = append([]string{digits}, groups...)
+	return sign + strings.Join(groups, c.Number.Group)
+}
+
+// Decimal formats a number with places digits after the point
+func (c *Catalog) Decimal(f float64, places int) string {
+	scale := math.Pow(10, float64(places))
+	scaled := int(math.Round(math.Abs(f) * scale))
+	whole := c.Int(scaled / int(scale))
+	if f < 0 && scaled != 0 {
+		whole = "-" + whole
+	}
+	if places == 0 {
+		return whole
+	}
+	return whole + c.Number.Decimal + fmt.Sprintf("%0*d", places, scaled%int(scale))
+}
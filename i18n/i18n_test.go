@@ -0,0 +1,131 @@
+package i18n
+
+import (
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/brantleyr/go-snake/assets"
+)
+
+// catalogs parses every catalog the game ships, English on its own
+func catalogs(t *testing.T) (*Catalog, []*Catalog) {
+	t.Helper()
+	paths, err := fs.Glob(assets.FS, path.Join("lang", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var english *Catalog
+	var all []*Catalog
+	for _, catalogPath := range paths {
+		src, err := fs.ReadFile(assets.FS, catalogPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		catalog, err := Parse(src)
+		if err != nil {
+			t.Fatalf("%s: %v", catalogPath, err)
+		}
+		if catalog.Lang == "en" {
+			english = catalog
+		}
+		all = append(all, catalog)
+	}
+	if english == nil {
+		t.Fatal("no en.json")
+	}
+	return english, all
+}
+
+func keys(c *Catalog) map[string]bool {
+	found := map[string]bool{}
+	for key := range c.Messages {
+		found[key] = true
+	}
+	return found
+}
+
+// Falling back to English is for mods, the shipped languages have everything
+func TestCatalogsMatchEnglish(t *testing.T) {
+	english, all := catalogs(t)
+	want := keys(english)
+	for _, catalog := range all {
+		got := keys(catalog)
+		missing, extra := []string{}, []string{}
+		for key := range want {
+			if !got[key] {
+				missing = append(missing, key)
+			}
+		}
+		for key := range got {
+			if !want[key] {
+				extra = append(extra, key)
+			}
+		}
+		sort.Strings(missing)
+		sort.Strings(extra)
+		if len(missing) > 0 {
+			t.Errorf("%s is missing %s", catalog.Lang, strings.Join(missing, ", "))
+		}
+		if len(extra) > 0 {
+			t.Errorf("%s has %s, which English doesn't", catalog.Lang, strings.Join(extra, ", "))
+		}
+
+		// Every message takes the same arguments as the English one
+		for key := range got {
+			if !want[key] {
+				continue
+			}
+			for form, text := range catalog.Messages[key].forms {
+				if verbs, englishVerbs := strings.Count(text, "%"), strings.Count(english.Messages[key].forms["other"], "%"); verbs != englishVerbs {
+					t.Errorf("%s %s %s has %d verbs, English has %d", catalog.Lang, key, form, verbs, englishVerbs)
+				}
+			}
+		}
+	}
+}
+
+func TestPluralForms(t *testing.T) {
+	// The forms each rule picks, and so needs a message for
+	wantForms := map[string]map[int]string{
+		OneOther: {0: "other", 1: "one", 2: "other", 5: "other"},
+		Other:    {0: "other", 1: "other", 2: "other", 5: "other"},
+	}
+	english, all := catalogs(t)
+	for _, catalog := range all {
+		forms, ok := wantForms[catalog.PluralRule]
+		if !ok {
+			t.Fatalf("%s: no forms to check for rule %q", catalog.Lang, catalog.PluralRule)
+		}
+		for key, englishMessage := range english.Messages {
+			if len(englishMessage.forms) == 1 {
+				continue
+			}
+			m, ok := catalog.Messages[key]
+			if !ok {
+				continue // TestCatalogsMatchEnglish says so
+			}
+			verbs := strings.Count(m.forms["other"], "%s")
+			for _, n := range []int{0, 1, 2, 5} {
+				form := forms[n]
+				if _, ok := m.forms[form]; !ok {
+					t.Errorf("%s %s has no %q form for %d", catalog.Lang, key, form, n)
+					continue
+				}
+				args := make([]interface{}, verbs)
+				for idx := range args {
+					args[idx] = catalog.Int(n)
+				}
+				got := catalog.Plural(key, n, args...)
+				if want := format(m.forms[form], args); got != want {
+					t.Errorf("%s %s for %d is %q, want the %s form %q", catalog.Lang, key, n, got, form, want)
+				}
+				if strings.Contains(got, "%!") {
+					t.Errorf("%s %s for %d doesn't format: %q", catalog.Lang, key, n, got)
+				}
+			}
+		}
+	}
+}
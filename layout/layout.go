@@ -0,0 +1,141 @@
+// Package layout places text on screen. Text is measured against its font
+// and anchored to an area, a corner, an edge or the middle, so it stays put
+// whatever the screen size and whatever size the font is.
+//
+// It only works out positions. Drawing is left to whoever has the screen,
+// the positions are the baseline starts that ebiten's text.Draw takes.
+package layout
+
+import (
+	"image"
+	"strings"
+
+	"golang.org/x/image/font"
+)
+
+// Anchor is the point of an area that text is lined up against
+type Anchor int
+
+const (
+	TopLeft Anchor = iota
+	Top
+	TopRight
+	Left
+	Center
+	Right
+	BottomLeft
+	Bottom
+	BottomRight
+)
+
+// column is 0 for the left, 1 for the middle and 2 for the right
+func (a Anchor) column() int {
+	return int(a) % 3
+}
+
+// row is 0 for the top, 1 for the middle and 2 for the bottom
+func (a Anchor) row() int {
+	return int(a) / 3
+}
+
+// Line is one line of placed text
+type Line struct {
+	Text string
+	Dot  image.Point // where its baseline starts
+}
+
+// LineHeight is how far apart lines of the font are
+func LineHeight(face font.Face) int {
+	return face.Metrics().Height.Ceil()
+}
+
+// Width is how far the text advances, the widest line if there are several
+func Width(face font.Face, s string) int {
+	widest := 0
+	for _, line := range strings.Split(s, "\n") {
+		if width := font.MeasureString(face, line).Ceil(); width > widest {
+			widest = width
+		}
+	}
+	return widest
+}
+
+// Measure is the size of a block of text, as wide as its widest line and
+// as tall as its lines
+func Measure(face font.Face, s string) image.Point {
+	return image.Pt(Width(face, s), (strings.Count(s, "\n")+1)*LineHeight(face))
+}
+
+// Inset shrinks an area by padding on every side
+func Inset(area image.Rectangle, padding int) image.Rectangle {
+	return image.Rect(area.Min.X+padding, area.Min.Y+padding, area.Max.X-padding, area.Max.Y-padding)
+}
+
+// Columns splits an area into n columns of the same width
+func Columns(area image.Rectangle, n int) []image.Rectangle {
+	columns := make([]image.Rectangle, n)
+	for idx := range columns {
+		columns[idx] = image.Rect(area.Min.X+area.Dx()*idx/n, area.Min.Y, area.Min.X+area.Dx()*(idx+1)/n, area.Max.Y)
+	}
+	return columns
+}
+
+// Align is where something of the given size goes against the anchor of
+// the area, padding in from its edges. Images are lined up with it the same
+// way Place lines up text.
+func Align(size image.Point, area image.Rectangle, anchor Anchor, padding int) image.Rectangle {
+	area = Inset(area, padding)
+	at := area.Min
+	switch anchor.column() {
+	case 1:
+		at.X = area.Min.X + (area.Dx()-size.X)/2
+	case 2:
+		at.X = area.Max.X - size.X
+	}
+	switch anchor.row() {
+	case 1:
+		at.Y = area.Min.Y + (area.Dy()-size.Y)/2
+	case 2:
+		at.Y = area.Max.Y - size.Y
+	}
+	return image.Rectangle{Min: at, Max: at.Add(size)}
+}
+
+// Place lines text up against the anchor of the area, padding in from its
+// edges. Each line is aligned the same way as the block: left, centered or
+// right.
+func Place(face font.Face, s string, area image.Rectangle, anchor Anchor, padding int) []Line {
+	area = Inset(area, padding)
+	lines := strings.Split(s, "\n")
+	lineHeight := LineHeight(face)
+	top := Align(image.Pt(0, len(lines)*lineHeight), area, anchor, 0).Min.Y
+
+	// The first baseline is the ascent down from the top of the block, with
+	// whatever the line gap is split above and below
+	ascent := face.Metrics().Ascent.Ceil()
+	gap := (lineHeight - ascent - face.Metrics().Descent.Ceil()) / 2
+
+	placed := make([]Line, len(lines))
+	for idx, line := range lines {
+		left := Align(image.Pt(font.MeasureString(face, line).Ceil(), 0), area, anchor, 0).Min.X
+		placed[idx] = Line{line, image.Pt(left, top+gap+ascent+idx*lineHeight)}
+	}
+	return placed
+}
+
+// Bounds is the area the placed lines take up
+func Bounds(face font.Face, lines []Line) image.Rectangle {
+	bounds := image.Rectangle{}
+	ascent := face.Metrics().Ascent.Ceil()
+	descent := face.Metrics().Descent.Ceil()
+	for idx, line := range lines {
+		width := font.MeasureString(face, line.Text).Ceil()
+		rect := image.Rect(line.Dot.X, line.Dot.Y-ascent, line.Dot.X+width, line.Dot.Y+descent)
+		if idx == 0 {
+			bounds = rect
+		} else {
+			bounds = bounds.Union(rect)
+		}
+	}
+	return bounds
+}
@@ -0,0 +1,114 @@
+package layout
+
+import (
+	"image"
+	"testing"
+
+	"golang.org/x/image/font/basicfont"
+)
+
+func TestAlign(t *testing.T) {
+	area := image.Rect(100, 50, 300, 150)
+	size := image.Pt(40, 20)
+	tests := []struct {
+		anchor  Anchor
+		padding int
+		want    image.Rectangle
+	}{
+		{TopLeft, 0, image.Rect(100, 50, 140, 70)},
+		{Top, 0, image.Rect(180, 50, 220, 70)},
+		{TopRight, 0, image.Rect(260, 50, 300, 70)},
+		{Left, 0, image.Rect(100, 90, 140, 110)},
+		{Center, 0, image.Rect(180, 90, 220, 110)},
+		{Right, 0, image.Rect(260, 90, 300, 110)},
+		{BottomLeft, 0, image.Rect(100, 130, 140, 150)},
+		{Bottom, 0, image.Rect(180, 130, 220, 150)},
+		{BottomRight, 0, image.Rect(260, 130, 300, 150)},
+		// Padding moves the corners and edges in, the middle stays put
+		{TopLeft, 10, image.Rect(110, 60, 150, 80)},
+		{Center, 10, image.Rect(180, 90, 220, 110)},
+		{BottomRight, 10, image.Rect(250, 120, 290, 140)},
+	}
+	for _, test := range tests {
+		if got := Align(size, area, test.anchor, test.padding); got != test.want {
+			t.Errorf("anchor %d padding %d: got %v, want %v", test.anchor, test.padding, got, test.want)
+		}
+	}
+}
+
+func TestInset(t *testing.T) {
+	tests := []struct {
+		area    image.Rectangle
+		padding int
+		want    image.Rectangle
+	}{
+		{image.Rect(0, 0, 100, 50), 0, image.Rect(0, 0, 100, 50)},
+		{image.Rect(0, 0, 100, 50), 10, image.Rect(10, 10, 90, 40)},
+		{image.Rect(20, 30, 60, 90), 5, image.Rect(25, 35, 55, 85)},
+		// A negative padding grows the area
+		{image.Rect(10, 10, 20, 20), -10, image.Rect(0, 0, 30, 30)},
+	}
+	for _, test := range tests {
+		if got := Inset(test.area, test.padding); got != test.want {
+			t.Errorf("Inset(%v, %d) = %v, want %v", test.area, test.padding, got, test.want)
+		}
+	}
+}
+
+func TestColumns(t *testing.T) {
+	tests := []struct {
+		area image.Rectangle
+		n    int
+		want []image.Rectangle
+	}{
+		{image.Rect(0, 0, 100, 10), 1, []image.Rectangle{image.Rect(0, 0, 100, 10)}},
+		{image.Rect(0, 0, 100, 10), 4, []image.Rectangle{
+			image.Rect(0, 0, 25, 10), image.Rect(25, 0, 50, 10), image.Rect(50, 0, 75, 10), image.Rect(75, 0, 100, 10)}},
+		// Widths that don't divide evenly still cover the area with no gaps
+		{image.Rect(10, 5, 20, 15), 3, []image.Rectangle{
+			image.Rect(10, 5, 13, 15), image.Rect(13, 5, 16, 15), image.Rect(16, 5, 20, 15)}},
+	}
+	for _, test := range tests {
+		got := Columns(test.area, test.n)
+		if len(got) != len(test.want) {
+			t.Fatalf("Columns(%v, %d) gave %d columns", test.area, test.n, len(got))
+		}
+		for idx := range got {
+			if got[idx] != test.want[idx] {
+				t.Errorf("Columns(%v, %d)[%d] = %v, want %v", test.area, test.n, idx, got[idx], test.want[idx])
+			}
+		}
+	}
+}
+
+// basicfont's 7x13 face is 7 pixels a letter and 13 a line, 11 of them above the baseline
+func TestPlace(t *testing.T) {
+	face := basicfont.Face7x13
+	area := image.Rect(0, 0, 200, 100)
+	tests := []struct {
+		s       string
+		anchor  Anchor
+		padding int
+		want    []image.Point
+	}{
+		{"abcd", TopLeft, 0, []image.Point{{0, 11}}},
+		{"abcd", Center, 0, []image.Point{{86, 54}}},
+		{"abcd", BottomRight, 0, []image.Point{{172, 98}}},
+		{"abcd", BottomRight, 10, []image.Point{{162, 88}}},
+		// Each line lines up on its own, the block as a whole against the anchor
+		{"ab\nabcd", Top, 0, []image.Point{{93, 11}, {86, 24}}},
+		{"ab\nabcd", Right, 0, []image.Point{{186, 48}, {172, 61}}},
+		{"ab\nabcd", BottomLeft, 5, []image.Point{{5, 80}, {5, 93}}},
+	}
+	for _, test := range tests {
+		lines := Place(face, test.s, area, test.anchor, test.padding)
+		if len(lines) != len(test.want) {
+			t.Fatalf("%q: %d lines, want %d", test.s, len(lines), len(test.want))
+		}
+		for idx, line := range lines {
+			if line.Dot != test.want[idx] {
+				t.Errorf("%q anchor %d padding %d: line %d at %v, want %v", test.s, test.anchor, test.padding, idx, line.Dot, test.want[idx])
+			}
+		}
+	}
+}
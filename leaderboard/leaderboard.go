@@ -0,0 +1,93 @@
+// Package leaderboard is a small shared high score table.
+//
+// Server keeps the scores in a JSON file and only accepts a score if the
+// replay sent with it earns exactly that score when played back through the
+// rules. Submit and Top are the client side, used by the game.
+package leaderboard
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brantleyr/go-snake/replay"
+)
+
+const (
+	DefaultPort  = "8090"
+	DefaultLimit = 10
+	maxNameLen   = 20
+)
+
+// Entry is one row of the table
+type Entry struct {
+	Name    string `json:"name"`
+	Mode    string `json:"mode"`
+	Score   int    `json:"score"`
+	Seconds int    `json:"seconds"`
+	Hash    string `json:"hash"`
+	Date    string `json:"date"`
+}
+
+// Submission is what the game sends when a run ends
+type Submission struct {
+	Name   string        `json:"name"`
+	Hash   string        `json:"hash"`
+	Replay replay.Replay `json:"replay"`
+}
+
+// SubmitResult is the server's answer to a submission
+type SubmitResult struct {
+	Rank  int    `json:"rank,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// Submit sends a finished run to the server at base, for example
+// "http://localhost:8090", and returns its rank in its mode
+func Submit(base string, name string, run *replay.Replay) (int, error) {
+	body, err := json.Marshal(Submission{Name: name, Hash: run.Hash(), Replay: *run})
+	if err != nil {
+		return 0, err
+	}
+	resp, err := httpClient.Post(strings.TrimRight(base, "/")+"/scores", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	var result SubmitResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("leaderboard: %s", resp.Status)
+	}
+	if result.Error != "" {
+		return 0, errors.New(result.Error)
+	}
+	return result.Rank, nil
+}
+
+// Top fetches the best scores for a mode
+func Top(base string, mode string, limit int) ([]Entry, error) {
+	query := url.Values{"mode": {mode}, "limit": {strconv.Itoa(limit)}}
+	resp, err := httpClient.Get(strings.TrimRight(base, "/") + "/scores?" + query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("leaderboard: %s", resp.Status)
+	}
+
+	var entries []Entry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,202 @@
+package leaderboard
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/brantleyr/go-snake/assets"
+	"github.com/brantleyr/go-snake/replay"
+	"github.com/brantleyr/go-snake/rules"
+)
+
+const maxSubmissionBytes = 1 << 20
+
+// Server is the HTTP side of the leaderboard:
+//
+//	GET  /scores?mode=normal&limit=10   best scores first
+//	POST /scores                        a Submission, answered with a SubmitResult
+type Server struct {
+	path    string
+	mu      sync.Mutex
+	entries []Entry
+	hashes  map[string]bool
+	curves  map[string]rules.Curve // each difficulty's, by mode
+	mux     *http.ServeMux
+}
+
+// NewServer loads the scores saved at path, starting empty if there are none yet
+func NewServer(path string) (*Server, error) {
+	s := &Server{path: path, hashes: map[string]bool{}, curves: map[string]rules.Curve{}, mux: http.NewServeMux()}
+	profiles, err := rules.LoadProfiles(assets.FS, "difficulty")
+	if err != nil {
+		return nil, err
+	}
+	for _, profile := range profiles {
+		s.curves[profile.ID] = profile.Curve
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &s.entries); err != nil {
+			return nil, err
+		}
+	}
+	for _, entry := range s.entries {
+		s.hashes[entry.Hash] = true
+	}
+	s.mux.HandleFunc("/scores", s.serveScores)
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) serveScores(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+		if err != nil || limit <= 0 {
+			limit = DefaultLimit
+		}
+		writeJSON(w, http.StatusOK, s.top(r.URL.Query().Get("mode"), limit))
+	case http.MethodPost:
+		s.submit(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeJSON(w, http.StatusMethodNotAllowed, SubmitResult{Error: "method not allowed"})
+	}
+}
+
+func (s *Server) submit(w http.ResponseWriter, r *http.Request) {
+	var sub Submission
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSubmissionBytes)).Decode(&sub); err != nil {
+		writeJSON(w, http.StatusBadRequest, SubmitResult{Error: "bad submission: " + err.Error()})
+		return
+	}
+	run := &sub.Replay
+	if sub.Hash != run.Hash() {
+		writeJSON(w, http.StatusBadRequest, SubmitResult{Error: "replay hash doesn't match the replay"})
+		return
+	}
+	if !s.onItsCurve(run) {
+		writeJSON(w, http.StatusUnprocessableEntity, SubmitResult{Error: "score rejected: " + run.Mode + " isn't played on that curve"})
+		return
+	}
+	// Play the run back, the score has to be exactly what the moves earn
+	if err := run.Verify(); err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, SubmitResult{Error: "score rejected: " + err.Error()})
+		return
+	}
+
+	name := strings.TrimSpace(sub.Name)
+	if name == "" {
+		name = "Player"
+	}
+	// Cut by characters, bytes could split one in half
+	if utf8.RuneCountInString(name) > maxNameLen {
+		name = string([]rune(name)[:maxNameLen])
+	}
+	entry := Entry{
+		Name:    name,
+		Mode:    run.Mode,
+		Score:   run.Score,
+		Seconds: run.Seconds,
+		Hash:    sub.Hash,
+		Date:    time.Now().UTC().Format("2006-01-02"),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.hashes[entry.Hash] {
+		writeJSON(w, http.StatusConflict, SubmitResult{Error: "this run was already submitted"})
+		return
+	}
+	s.entries = append(s.entries, entry)
+	if err := s.save(); err != nil {
+		// Take it back off, only what's on disk gets listed
+		s.entries = s.entries[:len(s.entries)-1]
+		writeJSON(w, http.StatusInternalServerError, SubmitResult{Error: "couldn't save the score"})
+		return
+	}
+	s.hashes[entry.Hash] = true
+	writeJSON(w, http.StatusCreated, SubmitResult{Rank: s.rank(entry)})
+}
+
+// onItsCurve reports whether a run was played on its mode's curve, so a
+// faster custom curve can't post its times under Normal. Custom modes are
+// named after their curve, which Verify checks.
+func (s *Server) onItsCurve(run *replay.Replay) bool {
+	if replay.IsCustomMode(run.Mode) {
+		return true
+	}
+	curve, ok := s.curves[run.Mode]
+	return ok && curve == run.Curve
+}
+
+// top is the best scores in a mode, higher score first and faster first on a tie
+func (s *Server) top(mode string, limit int) []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := []Entry{}
+	for _, entry := range s.entries {
+		if entry.Mode == mode {
+			entries = append(entries, entry)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Score != entries[j].Score {
+			return entries[i].Score > entries[j].Score
+		}
+		return entries[i].Seconds < entries[j].Seconds
+	})
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries
+}
+
+// rank is where an entry places in its mode, 1 being the best. Callers hold mu.
+func (s *Server) rank(entry Entry) int {
+	rank := 1
+	for _, other := range s.entries {
+		if other.Mode != entry.Mode || other.Hash == entry.Hash {
+			continue
+		}
+		if other.Score > entry.Score || (other.Score == entry.Score && other.Seconds < entry.Seconds) {
+			rank++
+		}
+	}
+	return rank
+}
+
+// save writes the scores to a temporary file first so a crash can't leave half a file
+func (s *Server) save() error {
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
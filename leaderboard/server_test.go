@@ -0,0 +1,246 @@
+package leaderboard
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/brantleyr/go-snake/replay"
+	"github.com/brantleyr/go-snake/rules"
+)
+
+// chase plays a normal run that heads straight for each apple until it has
+// eaten apples of them
+func chase(t *testing.T, seed int64, apples int) *replay.Replay {
+	t.Helper()
+	run := replay.New(replay.ModeNormal, rules.NormalCurve, seed)
+	arena := rules.NewArena(rules.DefaultWidth, rules.DefaultHeight, seed, 1)
+	snake := arena.Snakes[0]
+	distance := func(p rules.Point) int {
+		dx, dy := p.X-arena.Apple.X, p.Y-arena.Apple.Y
+		if dx < 0 {
+			dx = -dx
+		}
+		if dy < 0 {
+			dy = -dy
+		}
+		return dx + dy
+	}
+	for snake.Score < apples {
+		if arena.Over() || arena.Tick > 10000 {
+			t.Fatalf("seed %d: the chase died on %d apples", seed, snake.Score)
+		}
+		for _, direction := range []string{rules.Left, rules.Right, rules.Up, rules.Down} {
+			next := arena.Next(snake.Head(), direction)
+			if distance(next) < distance(snake.Head()) && arena.InBounds(next) && !arena.Occupied(next) {
+				snake.Direction = direction
+				break
+			}
+		}
+		run.Record(snake.Direction)
+		arena.Step()
+	}
+	run.Score = snake.Score
+	run.Seconds = len(run.Moves)
+	return run
+}
+
+func newTestServer(t *testing.T, path string) *httptest.Server {
+	t.Helper()
+	s, err := NewServer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestSubmitRanksAndLists(t *testing.T) {
+	ts := newTestServer(t, filepath.Join(t.TempDir(), "scores.json"))
+
+	two, three, four := chase(t, 1, 2), chase(t, 2, 3), chase(t, 3, 4)
+	for _, submit := range []struct {
+		name string
+		run  *replay.Replay
+		rank int
+	}{
+		{"ann", three, 1},
+		{"bob", two, 2},
+		{"cat", four, 1},
+	} {
+		rank, err := Submit(ts.URL, submit.name, submit.run)
+		if err != nil {
+			t.Fatalf("%s: %v", submit.name, err)
+		}
+		if rank != submit.rank {
+			t.Errorf("%s placed %d, want %d", submit.name, rank, submit.rank)
+		}
+	}
+
+	entries, err := Top(ts.URL, replay.ModeNormal, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Name != "cat" || entries[1].Name != "ann" {
+		t.Fatalf("top two are %+v, want cat then ann", entries)
+	}
+	if entries[0].Score != 4 || entries[0].Hash != four.Hash() {
+		t.Errorf("cat's entry is %+v", entries[0])
+	}
+	if entries, _ := Top(ts.URL, replay.ModeHard, DefaultLimit); len(entries) != 0 {
+		t.Errorf("hard has %d entries, want none", len(entries))
+	}
+}
+
+func TestSameScoreFasterRanksHigher(t *testing.T) {
+	ts := newTestServer(t, filepath.Join(t.TempDir(), "scores.json"))
+	slow, fast := chase(t, 1, 2), chase(t, 2, 2)
+	slow.Seconds, fast.Seconds = 100, 90
+
+	if _, err := Submit(ts.URL, "slow", slow); err != nil {
+		t.Fatal(err)
+	}
+	if rank, err := Submit(ts.URL, "fast", fast); err != nil || rank != 1 {
+		t.Fatalf("fast placed %d, %v, want 1", rank, err)
+	}
+}
+
+func TestSubmitRejects(t *testing.T) {
+	ts := newTestServer(t, filepath.Join(t.TempDir(), "scores.json"))
+	run := chase(t, 1, 2)
+	if _, err := Submit(ts.URL, "ann", run); err != nil {
+		t.Fatal(err)
+	}
+
+	post := func(sub Submission) (int, SubmitResult) {
+		body, _ := json.Marshal(sub)
+		resp, err := http.Post(ts.URL+"/scores", "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var result SubmitResult
+		json.NewDecoder(resp.Body).Decode(&result)
+		return resp.StatusCode, result
+	}
+	padded := *run
+	padded.Score++
+	other := chase(t, 2, 2)
+	offCurve := *other
+	offCurve.Curve.StartSpeed = 10
+	hardOnNormal := *other
+	hardOnNormal.Mode = replay.ModeHard
+	shrink := *other
+	shrink.Mode = "shrink"
+
+	tests := []struct {
+		name   string
+		sub    Submission
+		status int
+		error  string
+	}{
+		{"same run again", Submission{"bob", run.Hash(), *run}, http.StatusConflict, "already submitted"},
+		{"same run, different claim", Submission{"bob", padded.Hash(), padded}, http.StatusUnprocessableEntity, "score rejected"},
+		{"hash of another run", Submission{"bob", run.Hash(), *other}, http.StatusBadRequest, "hash doesn't match"},
+		{"no hash", Submission{"bob", "", *other}, http.StatusBadRequest, "hash doesn't match"},
+		{"faster curve than normal's", Submission{"bob", offCurve.Hash(), offCurve}, http.StatusUnprocessableEntity, "isn't played on that curve"},
+		{"hard on normal's curve", Submission{"bob", hardOnNormal.Hash(), hardOnNormal}, http.StatusUnprocessableEntity, "isn't played on that curve"},
+		{"a mode with no curve", Submission{"bob", shrink.Hash(), shrink}, http.StatusUnprocessableEntity, "isn't played on that curve"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status, result := post(test.sub)
+			if status != test.status || !strings.Contains(result.Error, test.error) {
+				t.Fatalf("got %d %q, want %d %q", status, result.Error, test.status, test.error)
+			}
+		})
+	}
+
+	resp, err := http.Post(ts.URL+"/scores", "application/json", strings.NewReader("{"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("broken JSON got %s", resp.Status)
+	}
+
+	if entries, _ := Top(ts.URL, replay.ModeNormal, DefaultLimit); len(entries) != 1 {
+		t.Errorf("%d entries after the rejects, want 1", len(entries))
+	}
+}
+
+func TestCustomCurvesRankApart(t *testing.T) {
+	ts := newTestServer(t, filepath.Join(t.TempDir(), "scores.json"))
+	slow, fast := chase(t, 1, 2), chase(t, 2, 3)
+	slow.Curve.Floor, fast.Curve.Floor = 8, 3
+	slow.Mode, fast.Mode = replay.CustomMode(slow.Curve), replay.CustomMode(fast.Curve)
+
+	for _, run := range []*replay.Replay{slow, fast} {
+		if rank, err := Submit(ts.URL, "ann", run); err != nil || rank != 1 {
+			t.Fatalf("%s placed %d, %v, want 1 on its own table", run.Mode, rank, err)
+		}
+	}
+	if entries, _ := Top(ts.URL, slow.Mode, DefaultLimit); len(entries) != 1 || entries[0].Score != 2 {
+		t.Errorf("%s has %+v, want just the slow run", slow.Mode, entries)
+	}
+}
+
+func TestScoresSurviveARestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scores.json")
+	run := chase(t, 1, 2)
+	if _, err := Submit(newTestServer(t, path).URL, "ann", run); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted := newTestServer(t, path)
+	if entries, err := Top(restarted.URL, replay.ModeNormal, DefaultLimit); err != nil || len(entries) != 1 {
+		t.Fatalf("got %v, %v after a restart, want ann's entry", entries, err)
+	}
+	if _, err := Submit(restarted.URL, "ann", run); err == nil {
+		t.Error("a restarted server took the same run again")
+	}
+}
+
+func TestFailedSaveListsNothing(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "not-made-yet")
+	ts := newTestServer(t, filepath.Join(dir, "scores.json"))
+	run := chase(t, 1, 2)
+
+	if _, err := Submit(ts.URL, "ann", run); err == nil {
+		t.Fatal("the score went in with nowhere to save it")
+	}
+	if entries, _ := Top(ts.URL, replay.ModeNormal, DefaultLimit); len(entries) != 0 {
+		t.Fatalf("listed %v after the save failed", entries)
+	}
+
+	// Once it can be saved the same run goes in, it was never taken
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if rank, err := Submit(ts.URL, "ann", run); err != nil || rank != 1 {
+		t.Fatalf("resubmitting got rank %d, %v", rank, err)
+	}
+}
+
+func TestLongNamesKeepWholeCharacters(t *testing.T) {
+	ts := newTestServer(t, filepath.Join(t.TempDir(), "scores.json"))
+	name := strings.Repeat("ü", maxNameLen+5)
+	if _, err := Submit(ts.URL, name, chase(t, 1, 2)); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := Top(ts.URL, replay.ModeNormal, DefaultLimit)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("got %v, %v", entries, err)
+	}
+	if got := entries[0].Name; !utf8.ValidString(got) || got != strings.Repeat("ü", maxNameLen) {
+		t.Errorf("name cut to %q, want %d whole characters", got, maxNameLen)
+	}
+}
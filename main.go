@@ -1,13 +1,39 @@
 package main
 
 import (
+	"flag"
 	"log"
 
 	"github.com/brantleyr/go-snake/game"
+	"github.com/brantleyr/go-snake/replay"
+	"github.com/brantleyr/go-snake/replaygif"
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
 func main() {
+	spectateAddr := flag.String("spectate", "", "serve a live spectator view on this address, for example :8080")
+	exportGIF := flag.String("export-gif", "", "render a saved replay to a GIF and quit, without a window: --export-gif run.replay.json out.gif. cmd/snake-gif does the same on machines with no display")
+	loadScreenshot := flag.String("load-screenshot", "", "start paused where a screenshot was taken, for debugging")
+	leaderboardURL := flag.String("leaderboard", "", "submit scores to the leaderboard server at this URL, for example http://localhost:8090")
+	debug := flag.Bool("debug", false, "turn on the F3 overlay and the backquote developer console")
+	flag.Parse()
+
+	// Render a replay without playing
+	if *exportGIF != "" {
+		out := flag.Arg(0)
+		if out == "" {
+			out = "replay.gif"
+		}
+		run, err := replay.Load(*exportGIF)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := replaygif.Write(run, out, replaygif.DefaultCellSize); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	// Set window size
 	ebiten.SetWindowSize(game.ScreenWidth, game.ScreenHeight)
 
@@ -17,14 +43,31 @@ func main() {
 	// WindowResizingModeEnabled if we want this in the future
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeDisabled)
 
-	// Set game state
-	game.GameState = "intro"
-	game.GameStarted = false
-	game.GamePaused = false
-	game.GameOver = false
+	// Start on the intro
+	game.ShowScreen("intro")
+	if *loadScreenshot != "" {
+		if err := game.LoadScreenshot(*loadScreenshot); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Spectators
+	if *spectateAddr != "" {
+		addr, err := game.StartSpectating(*spectateAddr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Spectators can watch at http://%s/", addr)
+	}
+
+	// Shared leaderboard
+	game.LeaderboardURL = *leaderboardURL
+
+	// Developer tools
+	game.DebugMode = *debug
 
 	// Run the game
-	if err := ebiten.RunGame(&game.Game{}); err != nil {
+	if err := ebiten.RunGame(&game.Game{}); err != nil && err != game.ErrQuit {
 		log.Fatal(err)
 	}
 }
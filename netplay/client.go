@@ -0,0 +1,259 @@
+package netplay
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/brantleyr/go-snake/rules"
+)
+
+const (
+	PhaseLobby   = "lobby"
+	PhasePlaying = "playing"
+	PhaseEnded   = "ended"
+)
+
+type pendingTurn struct {
+	seq int
+	dir string
+}
+
+// Client is one player's view of a server. The read goroutine only queues
+// messages; Poll applies them, so all state is touched from the caller's
+// goroutine, which for the game is Ebiten's Update.
+type Client struct {
+	Slot    int
+	Players []Player
+	Phase   string
+	Winner  int // snake ID, -1 for nobody
+	State   State
+	Err     error
+
+	conn      net.Conn
+	enc       *json.Encoder
+	incoming  chan Message
+	readErr   chan error
+	done      chan struct{} // closed by Close, so the reader never waits on incoming for good
+	closeOnce sync.Once
+
+	seq      int
+	pending  []pendingTurn
+	lastMove time.Time
+}
+
+// Dial joins the server at addr under the given name
+func Dial(addr string, name string) (*Client, error) {
+	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{
+		Slot:     -1,
+		Phase:    PhaseLobby,
+		Winner:   -1,
+		conn:     conn,
+		enc:      json.NewEncoder(conn),
+		incoming: make(chan Message, 64),
+		readErr:  make(chan error, 1),
+		done:     make(chan struct{}),
+	}
+	if err := c.send(Message{Type: msgHello, Name: name}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	go c.read()
+	return c, nil
+}
+
+func (c *Client) read() {
+	defer close(c.incoming)
+	scanner := bufio.NewScanner(c.conn)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var msg Message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			continue
+		}
+		// Once the game stops polling nobody takes these, so give up when it closes
+		select {
+		case c.incoming <- msg:
+		case <-c.done:
+			c.readErr <- net.ErrClosed
+			return
+		}
+	}
+	err := scanner.Err()
+	if err == nil {
+		err = errors.New("disconnected from host")
+	}
+	c.readErr <- err
+}
+
+// send writes one message, giving up on a host that stopped reading
+func (c *Client) send(msg Message) error {
+	c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return c.enc.Encode(msg)
+}
+
+// Close leaves the game
+func (c *Client) Close() error {
+	c.closeOnce.Do(func() { close(c.done) })
+	return c.conn.Close()
+}
+
+// Poll applies everything the server sent since the last call
+func (c *Client) Poll() {
+	for {
+		select {
+		case msg, ok := <-c.incoming:
+			if !ok {
+				if c.Err == nil {
+					c.Err = <-c.readErr
+				}
+				return
+			}
+			c.apply(msg)
+		default:
+			return
+		}
+	}
+}
+
+func (c *Client) apply(msg Message) {
+	switch msg.Type {
+	case msgWelcome:
+		c.Slot = msg.Slot
+	case msgLobby:
+		c.Players = msg.Players
+	case msgError:
+		c.Err = errors.New(msg.Error)
+	case msgBegin:
+		c.Players = msg.Players
+		c.State = *msg.State
+		c.Phase = PhasePlaying
+		c.Winner = -1
+		c.pending = nil
+		c.lastMove = time.Now()
+	case msgDelta:
+		c.applyDelta(msg.Delta)
+	case msgEnd:
+		c.Phase = PhaseEnded
+		c.Winner = msg.Winner
+	}
+}
+
+func (c *Client) applyDelta(delta *Delta) {
+	c.State.Tick = delta.Tick
+	c.State.Apple = delta.Apple
+	c.State.Speed = delta.Speed
+	c.State.Interval = delta.Interval
+	c.lastMove = time.Now()
+
+	for _, change := range delta.Snakes {
+		for idx := range c.State.Snakes {
+			snake := &c.State.Snakes[idx]
+			if snake.ID != change.ID || !snake.Alive {
+				continue
+			}
+			snake.Direction = change.Direction
+			snake.Score = change.Score
+			snake.Alive = change.Alive
+			if !change.Alive {
+				snake.Body = nil
+				continue
+			}
+			snake.Body = append([]rules.Point{change.Head}, snake.Body...)
+			if !change.Grew {
+				snake.Body = snake.Body[:len(snake.Body)-1]
+			}
+			if snake.ID == c.SnakeID() {
+				c.dropAcked(change.Ack)
+			}
+		}
+	}
+}
+
+func (c *Client) dropAcked(ack int) {
+	kept := c.pending[:0]
+	for _, turn := range c.pending {
+		if turn.seq > ack {
+			kept = append(kept, turn)
+		}
+	}
+	c.pending = kept
+}
+
+// SnakeID is the arena snake this client controls, -1 if it isn't playing
+func (c *Client) SnakeID() int {
+	for _, player := range c.Players {
+		if player.Slot == c.Slot {
+			return player.Snake
+		}
+	}
+	return -1
+}
+
+// IsHost reports whether this client may start the game
+func (c *Client) IsHost() bool {
+	return len(c.Players) > 0 && c.Players[0].Slot == c.Slot
+}
+
+// Start asks the server to begin, only the host's request counts
+func (c *Client) Start() {
+	c.send(Message{Type: msgStart})
+}
+
+// Turn sends a turn to the server and starts showing it straight away
+func (c *Client) Turn(dir string) {
+	if c.Phase != PhasePlaying {
+		return
+	}
+	current := c.predictedDirection()
+	if (dir == rules.Up || dir == rules.Down) && (current == rules.Up || current == rules.Down) {
+		return
+	}
+	if (dir == rules.Left || dir == rules.Right) && (current == rules.Left || current == rules.Right) {
+		return
+	}
+	c.seq++
+	c.pending = append(c.pending, pendingTurn{c.seq, dir})
+	c.send(Message{Type: msgTurn, Dir: dir, Seq: c.seq})
+}
+
+// predictedDirection is the last turn we sent, or what the server last said
+func (c *Client) predictedDirection() string {
+	if len(c.pending) > 0 {
+		return c.pending[len(c.pending)-1].dir
+	}
+	for _, snake := range c.State.Snakes {
+		if snake.ID == c.SnakeID() {
+			return snake.Direction
+		}
+	}
+	return ""
+}
+
+// Snakes returns every snake for drawing. The local snake is predicted:
+// it faces the way the player last turned, and if the next move from the
+// server is late it is drawn one cell further on so input doesn't feel laggy.
+// The next delta from the server always replaces the guess.
+func (c *Client) Snakes() []SnakeState {
+	snakes := make([]SnakeState, 0, len(c.State.Snakes))
+	for _, snake := range c.State.Snakes {
+		if snake.ID == c.SnakeID() && snake.Alive && c.Phase == PhasePlaying {
+			snake.Direction = c.predictedDirection()
+			overdue := time.Since(c.lastMove) > time.Duration(c.State.Interval)*time.Millisecond
+			if overdue && len(snake.Body) > 0 {
+				arena := rules.Arena{Width: c.State.Width, Height: c.State.Height}
+				head := arena.Next(snake.Body[0], snake.Direction)
+				snake.Body = append([]rules.Point{head}, snake.Body[:len(snake.Body)-1]...)
+			}
+		}
+		snakes = append(snakes, snake)
+	}
+	return snakes
+}
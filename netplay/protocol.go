@@ -0,0 +1,108 @@
+// Package netplay runs multiplayer snake over TCP.
+//
+// One Server owns the only real Arena and moves it on its own clock. Every
+// player, including the one hosting, connects to it with a Client and sends
+// nothing but turns. After every move the server sends each client what
+// changed, and the client guesses where its own snake is in between.
+//
+// Messages are JSON, one per line, so a session can be watched or faked
+// with nc on localhost.
+package netplay
+
+import (
+	"github.com/brantleyr/go-snake/rules"
+)
+
+const (
+	DefaultPort = "7777"
+	MinPlayers  = 2
+	MaxPlayers  = 4
+
+	msgHello   = "hello"   // client -> server, Name
+	msgTurn    = "turn"    // client -> server, Dir and Seq
+	msgStart   = "start"   // client -> server, only the host may start
+	msgWelcome = "welcome" // server -> client, Slot
+	msgLobby   = "lobby"   // server -> client, Players
+	msgBegin   = "begin"   // server -> client, Players and the full State
+	msgDelta   = "delta"   // server -> client, Delta after each move
+	msgEnd     = "end"     // server -> client, Winner
+	msgError   = "error"   // server -> client, Error, then the connection closes
+)
+
+type Player struct {
+	Slot  int    `json:"slot"`
+	Name  string `json:"name"`
+	Snake int    `json:"snake"` // snake ID in the arena while a game is running
+}
+
+type SnakeState struct {
+	ID        int           `json:"id"`
+	Body      []rules.Point `json:"body"`
+	Direction string        `json:"dir"`
+	Alive     bool          `json:"alive"`
+	Score     int           `json:"score"`
+}
+
+type State struct {
+	Width    int          `json:"width"`
+	Height   int          `json:"height"`
+	Tick     int          `json:"tick"`
+	Apple    rules.Point  `json:"apple"`
+	Speed    int          `json:"speed"`
+	Interval int          `json:"interval"` // milliseconds until the next move
+	Snakes   []SnakeState `json:"snakes"`
+}
+
+// SnakeDelta is what happened to one snake on one move. Clients push the new
+// head on and drop the tail unless the snake grew.
+type SnakeDelta struct {
+	ID        int         `json:"id"`
+	Head      rules.Point `json:"head"`
+	Grew      bool        `json:"grew"`
+	Alive     bool        `json:"alive"`
+	Direction string      `json:"dir"`
+	Score     int         `json:"score"`
+	Ack       int         `json:"ack"` // last turn Seq the server applied for this snake
+}
+
+type Delta struct {
+	Tick     int          `json:"tick"`
+	Apple    rules.Point  `json:"apple"`
+	Speed    int          `json:"speed"`
+	Interval int          `json:"interval"`
+	Snakes   []SnakeDelta `json:"snakes"`
+}
+
+type Message struct {
+	Type    string   `json:"type"`
+	Name    string   `json:"name,omitempty"`
+	Dir     string   `json:"dir,omitempty"`
+	Seq     int      `json:"seq,omitempty"`
+	Slot    int      `json:"slot"`
+	Winner  int      `json:"winner"`
+	Error   string   `json:"error,omitempty"`
+	Players []Player `json:"players,omitempty"`
+	State   *State   `json:"state,omitempty"`
+	Delta   *Delta   `json:"delta,omitempty"`
+}
+
+func stateOf(arena *rules.Arena) *State {
+	state := &State{
+		Width:    arena.Width,
+		Height:   arena.Height,
+		Tick:     arena.Tick,
+		Apple:    arena.Apple,
+		Speed:    arena.Ramp.Shown,
+		Interval: arena.MoveInterval(),
+	}
+	for _, s := range arena.Snakes {
+		state.Snakes = append(state.Snakes, SnakeState{
+			ID:        s.ID,
+			Body:      append([]rules.Point(nil), s.Body...),
+			Direction: s.Direction,
+			Alive:     s.Alive,
+			Score:     s.Score,
+		})
+	}
+	return state
+}
@@ -0,0 +1,290 @@
+package netplay
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/brantleyr/go-snake/rules"
+)
+
+const writeTimeout = 2 * time.Second
+
+type peer struct {
+	slot int
+	name string
+	conn net.Conn
+	enc  *json.Encoder
+}
+
+type event struct {
+	kind string // join, leave, or a client message type
+	peer *peer
+	msg  Message
+}
+
+type queuedTurn struct {
+	snake int
+	dir   string
+	seq   int
+}
+
+// Server hosts one arena. Everything about the game lives on the run
+// goroutine, the connection goroutines only pass messages to it.
+type Server struct {
+	listener net.Listener
+	events   chan event
+	done     chan struct{}
+
+	// Owned by run
+	peers   map[int]*peer
+	arena   *rules.Arena
+	snakeOf map[int]int // slot -> snake ID for the running game
+	turns   []queuedTurn
+	acks    map[int]int // snake ID -> last applied Seq
+}
+
+// Listen starts a server on addr, for example ":7777"
+func Listen(addr string) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		listener: listener,
+		events:   make(chan event),
+		done:     make(chan struct{}),
+		peers:    map[int]*peer{},
+	}
+	go s.accept()
+	go s.run()
+	return s, nil
+}
+
+// Addr is the address the server ended up listening on
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close stops the server and drops every player
+func (s *Server) Close() error {
+	select {
+	case <-s.done:
+		return nil
+	default:
+	}
+	close(s.done)
+	return s.listener.Close()
+}
+
+func (s *Server) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.read(conn)
+	}
+}
+
+// read waits for the hello, then forwards everything the client sends
+func (s *Server) read(conn net.Conn) {
+	p := &peer{slot: -1, conn: conn, enc: json.NewEncoder(conn)}
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		var msg Message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			continue
+		}
+		kind := msg.Type
+		if kind == msgHello {
+			kind = "join"
+		}
+		if !s.send(event{kind, p, msg}) {
+			break
+		}
+	}
+	s.send(event{"leave", p, Message{}})
+	conn.Close()
+}
+
+func (s *Server) send(ev event) bool {
+	select {
+	case s.events <- ev:
+		return true
+	case <-s.done:
+		return false
+	}
+}
+
+func (s *Server) run() {
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			for _, p := range s.peers {
+				p.conn.Close()
+			}
+			return
+		case ev := <-s.events:
+			s.handle(ev, timer)
+		case <-timer.C:
+			s.tick()
+			if s.arena != nil {
+				timer.Reset(time.Duration(s.arena.MoveInterval()) * time.Millisecond)
+			}
+		}
+	}
+}
+
+func (s *Server) handle(ev event, timer *time.Timer) {
+	switch ev.kind {
+	case "join":
+		s.join(ev.peer, ev.msg.Name)
+	case "leave":
+		if ev.peer.slot < 0 || s.peers[ev.peer.slot] != ev.peer {
+			return
+		}
+		delete(s.peers, ev.peer.slot)
+		if snake, ok := s.snakeOf[ev.peer.slot]; ok && s.arena != nil {
+			s.arena.Remove(snake)
+		}
+		s.broadcast(Message{Type: msgLobby, Players: s.players()})
+	case msgTurn:
+		if snake, ok := s.snakeOf[ev.peer.slot]; ok && s.arena != nil {
+			s.turns = append(s.turns, queuedTurn{snake, ev.msg.Dir, ev.msg.Seq})
+		}
+	case msgStart:
+		if s.arena != nil || ev.peer.slot != s.hostSlot() || len(s.peers) < MinPlayers {
+			return
+		}
+		s.begin()
+		timer.Reset(time.Duration(s.arena.MoveInterval()) * time.Millisecond)
+	}
+}
+
+func (s *Server) join(p *peer, name string) {
+	if s.arena != nil {
+		s.reject(p, "a game is already running")
+		return
+	}
+	for slot := 0; slot < MaxPlayers; slot++ {
+		if _, taken := s.peers[slot]; !taken {
+			p.slot = slot
+			p.name = name
+			s.peers[slot] = p
+			s.write(p, Message{Type: msgWelcome, Slot: slot})
+			s.broadcast(Message{Type: msgLobby, Players: s.players()})
+			return
+		}
+	}
+	s.reject(p, "the game is full")
+}
+
+func (s *Server) reject(p *peer, reason string) {
+	s.write(p, Message{Type: msgError, Error: reason})
+	p.conn.Close()
+}
+
+// hostSlot is the lowest slot, which is the player who started the server
+func (s *Server) hostSlot() int {
+	for slot := 0; slot < MaxPlayers; slot++ {
+		if _, ok := s.peers[slot]; ok {
+			return slot
+		}
+	}
+	return -1
+}
+
+func (s *Server) players() []Player {
+	players := []Player{}
+	for slot, p := range s.peers {
+		snake := -1
+		if id, ok := s.snakeOf[slot]; ok {
+			snake = id
+		}
+		players = append(players, Player{Slot: slot, Name: p.name, Snake: snake})
+	}
+	sort.Slice(players, func(i, j int) bool { return players[i].Slot < players[j].Slot })
+	return players
+}
+
+func (s *Server) begin() {
+	s.arena = rules.NewArena(rules.DefaultWidth, rules.DefaultHeight, time.Now().UnixNano(), len(s.peers))
+	s.snakeOf = map[int]int{}
+	s.acks = map[int]int{}
+	s.turns = nil
+	for idx, player := range s.players() {
+		s.snakeOf[player.Slot] = idx
+	}
+	s.broadcast(Message{Type: msgBegin, Players: s.players(), State: stateOf(s.arena)})
+}
+
+// tick applies the turns that came in since the last move, moves the arena
+// and tells everyone what changed
+func (s *Server) tick() {
+	if s.arena == nil {
+		return
+	}
+	for _, turn := range s.turns {
+		s.arena.Turn(turn.snake, turn.dir)
+		s.acks[turn.snake] = turn.seq
+	}
+	s.turns = nil
+
+	lengths := map[int]int{}
+	for _, snake := range s.arena.Snakes {
+		lengths[snake.ID] = len(snake.Body)
+	}
+	s.arena.Step()
+
+	delta := &Delta{
+		Tick:     s.arena.Tick,
+		Apple:    s.arena.Apple,
+		Speed:    s.arena.Ramp.Shown,
+		Interval: s.arena.MoveInterval(),
+	}
+	for _, snake := range s.arena.Snakes {
+		delta.Snakes = append(delta.Snakes, SnakeDelta{
+			ID:        snake.ID,
+			Head:      snake.Head(),
+			Grew:      len(snake.Body) > lengths[snake.ID],
+			Alive:     snake.Alive,
+			Direction: snake.Direction,
+			Score:     snake.Score,
+			Ack:       s.acks[snake.ID],
+		})
+	}
+	s.broadcast(Message{Type: msgDelta, Delta: delta})
+
+	if s.arena.Over() {
+		winner := -1
+		for _, snake := range s.arena.Snakes {
+			if snake.Alive {
+				winner = snake.ID
+			}
+		}
+		s.arena = nil
+		s.snakeOf = nil
+		s.broadcast(Message{Type: msgEnd, Winner: winner})
+		s.broadcast(Message{Type: msgLobby, Players: s.players()})
+	}
+}
+
+func (s *Server) broadcast(msg Message) {
+	for _, p := range s.peers {
+		s.write(p, msg)
+	}
+}
+
+// write drops a player that can't keep up instead of stalling the game
+func (s *Server) write(p *peer, msg Message) {
+	p.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if err := p.enc.Encode(msg); err != nil {
+		p.conn.Close()
+	}
+}
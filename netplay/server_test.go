@@ -0,0 +1,176 @@
+package netplay
+
+import (
+	"encoding/json"
+	"net"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/brantleyr/go-snake/rules"
+)
+
+// waitFor polls the clients until done says so, or fails the test
+func waitFor(t *testing.T, what string, clients []*Client, done func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, c := range clients {
+			c.Poll()
+			if c.Err != nil {
+				t.Fatalf("waiting for %s: client %d: %v", what, c.Slot, c.Err)
+			}
+		}
+		if done() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func snakeByID(c *Client, id int) SnakeState {
+	for _, snake := range c.State.Snakes {
+		if snake.ID == id {
+			return snake
+		}
+	}
+	return SnakeState{}
+}
+
+func TestGameOverLoopback(t *testing.T) {
+	server, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	// Join one at a time so the slots come out in order
+	clients := []*Client{}
+	for _, name := range []string{"ann", "bob", "cat"} {
+		c, err := Dial(server.Addr(), name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+		clients = append(clients, c)
+		waitFor(t, name+"'s welcome", clients, func() bool { return c.Slot == len(clients)-1 })
+	}
+	waitFor(t, "the lobby", clients, func() bool {
+		for _, c := range clients {
+			if len(c.Players) != 3 {
+				return false
+			}
+		}
+		return true
+	})
+	if !clients[0].IsHost() || clients[1].IsHost() {
+		t.Fatalf("host is slot %d, want slot 0", clients[0].Players[0].Slot)
+	}
+
+	// Only the host can start
+	clients[1].Start()
+	time.Sleep(50 * time.Millisecond)
+	for _, c := range clients {
+		c.Poll()
+		if c.Phase != PhaseLobby {
+			t.Fatalf("client %d is %s after a guest asked to start", c.Slot, c.Phase)
+		}
+	}
+	clients[0].Start()
+	waitFor(t, "the game to begin", clients, func() bool {
+		for _, c := range clients {
+			if c.Phase != PhasePlaying {
+				return false
+			}
+		}
+		return true
+	})
+	for idx, c := range clients {
+		if c.SnakeID() != idx {
+			t.Fatalf("client %d has snake %d", idx, c.SnakeID())
+		}
+	}
+
+	late, err := Dial(server.Addr(), "dan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer late.Close()
+
+	// Snakes 0 and 2 turn into the side walls. Snake 1 heads up the right
+	// side and turns left then down before one move, which must not fold it
+	// back into its neck.
+	clients[0].Turn(rules.Left)
+	clients[2].Turn(rules.Right)
+	clients[1].Turn(rules.Left)
+	clients[1].Turn(rules.Down)
+
+	waitFor(t, "snake 1's turns to be acked", clients, func() bool {
+		return len(clients[1].pending) == 0
+	})
+	waitFor(t, "the game to end", clients, func() bool {
+		for _, c := range clients {
+			if c.Phase != PhaseEnded {
+				return false
+			}
+		}
+		return true
+	})
+	for _, c := range clients {
+		if c.Winner != 1 {
+			t.Errorf("client %d saw snake %d win, want snake 1", c.Slot, c.Winner)
+		}
+	}
+	if snake := snakeByID(clients[1], 1); !snake.Alive || snake.Direction == rules.Up {
+		t.Errorf("snake 1 is alive %v heading %s, want it alive and turned", snake.Alive, snake.Direction)
+	}
+	waitFor(t, "the lobby after the game", clients, func() bool {
+		return len(clients[0].Players) == 3 && clients[0].SnakeID() == -1
+	})
+
+	waitFor(t, "the late player to be turned away", nil, func() bool {
+		late.Poll()
+		return late.Err != nil
+	})
+	if late.Err.Error() != "a game is already running" {
+		t.Errorf("late player got %q", late.Err)
+	}
+}
+
+func TestCloseEndsTheReader(t *testing.T) {
+	// A host that keeps talking to a client nobody polls any more
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		enc := json.NewEncoder(conn)
+		for enc.Encode(Message{Type: msgLobby}) == nil {
+		}
+	}()
+
+	before := runtime.NumGoroutine()
+	c, err := Dial(listener.Addr().String(), "ann")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for len(c.incoming) < cap(c.incoming) {
+		time.Sleep(time.Millisecond)
+	}
+	c.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines left after Close, started with %d", runtime.NumGoroutine(), before)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,88 @@
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/brantleyr/go-snake/rules"
+)
+
+// Match is a replay of a game with several snakes, like a bot match. Moves
+// holds one string per snake, one letter per tick it was alive for.
+type Match struct {
+	Version int      `json:"version"`
+	Seed    int64    `json:"seed"`
+	Width   int      `json:"width"`
+	Height  int      `json:"height"`
+	Players []string `json:"players"`
+	Moves   []string `json:"moves"`
+	Winner  int      `json:"winner"` // snake ID, -1 for a draw
+	Scores  []int    `json:"scores"`
+}
+
+// NewMatch starts an empty match replay for the given players, in snake ID order
+func NewMatch(seed int64, width int, height int, players []string) *Match {
+	return &Match{
+		Version: Version,
+		Seed:    seed,
+		Width:   width,
+		Height:  height,
+		Players: players,
+		Moves:   make([]string, len(players)),
+		Winner:  -1,
+	}
+}
+
+// Record adds one move for a snake
+func (m *Match) Record(id int, direction string) {
+	if letter, ok := directionLetters[direction]; ok {
+		m.Moves[id] += string(letter)
+	}
+}
+
+// Simulate plays the match back through the rules
+func (m *Match) Simulate() (*rules.Arena, error) {
+	arena := rules.NewArena(m.Width, m.Height, m.Seed, len(m.Players))
+	for !arena.Over() {
+		moved := false
+		for _, snake := range arena.Snakes {
+			if !snake.Alive || arena.Tick >= len(m.Moves[snake.ID]) {
+				continue
+			}
+			direction := directionOf(m.Moves[snake.ID][arena.Tick])
+			if direction == "" {
+				return nil, fmt.Errorf("snake %d move %d: bad direction %q", snake.ID, arena.Tick, m.Moves[snake.ID][arena.Tick])
+			}
+			arena.SetDirection(snake.ID, direction)
+			moved = true
+		}
+		if !moved {
+			break
+		}
+		arena.Step()
+	}
+	return arena, nil
+}
+
+// Save writes the match as JSON
+func (m *Match) Save(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// LoadMatch reads a match saved with Save
+func LoadMatch(path string) (*Match, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m := &Match{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
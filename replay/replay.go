@@ -0,0 +1,167 @@
+// Package replay records single player runs and checks them against the rules.
+//
+// A replay is the seed the run started from, the difficulty curve it was
+// played on and the direction of every move the snake made, one letter per
+// move. Since apples come from the seed and everything else follows from the
+// moves, playing the moves back through package rules gives the same score
+// the player saw, and the curve gives the least time it could have taken.
+package replay
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/brantleyr/go-snake/rules"
+)
+
+const (
+	Version = 2 // 2 added the curve
+
+	ModeNormal = "normal"
+	ModeHard   = "hard"
+	modeCustom = "custom-"
+)
+
+var directionLetters = map[string]byte{
+	rules.Up:    'U',
+	rules.Down:  'D',
+	rules.Left:  'L',
+	rules.Right: 'R',
+}
+
+type Replay struct {
+	Version int         `json:"version"`
+	Mode    string      `json:"mode"` // the difficulty's ID, or CustomMode of the curve
+	Curve   rules.Curve `json:"curve"`
+	Seed    int64       `json:"seed"`
+	Moves   string      `json:"moves"`
+	Score   int         `json:"score"`
+	Seconds int         `json:"seconds"`
+}
+
+// New starts an empty replay. Runs on a difficulty's curve can be played
+// back, other modes leave it empty.
+func New(mode string, curve rules.Curve, seed int64) *Replay {
+	return &Replay{Version: Version, Mode: mode, Curve: curve, Seed: seed}
+}
+
+// CustomMode is the mode of a run on numbers the player picked. Every set of
+// numbers gets its own, so they don't share a score table.
+func CustomMode(curve rules.Curve) string {
+	sum := sha256.Sum256([]byte(curveKey(curve)))
+	return modeCustom + hex.EncodeToString(sum[:4])
+}
+
+// IsCustomMode reports whether mode came from CustomMode
+func IsCustomMode(mode string) bool {
+	return strings.HasPrefix(mode, modeCustom)
+}
+
+func curveKey(c rules.Curve) string {
+	return fmt.Sprintf("%d,%d,%d,%d,%d", c.StartSpeed, c.Step, c.Interval, c.Floor, c.Grace)
+}
+
+// Record adds one move in the given direction
+func (r *Replay) Record(direction string) {
+	if letter, ok := directionLetters[direction]; ok {
+		r.Moves += string(letter)
+	}
+}
+
+// Direction is the direction of the move at the given index
+func (r *Replay) Direction(move int) string {
+	return directionOf(r.Moves[move])
+}
+
+func directionOf(letter byte) string {
+	for direction, known := range directionLetters {
+		if letter == known {
+			return direction
+		}
+	}
+	return ""
+}
+
+// Hash identifies the run by what was played, not by the score claimed for it
+func (r *Replay) Hash() string {
+	sum := sha256.Sum256([]byte(r.Mode + "|" + curveKey(r.Curve) + "|" + strconv.FormatInt(r.Seed, 10) + "|" + r.Moves))
+	return hex.EncodeToString(sum[:])
+}
+
+// Verifiable reports whether the run can be checked by Simulate, which is
+// any run of this version with a curve. Other modes depend on walls, hazards
+// or timers the moves alone don't capture.
+func (r *Replay) Verifiable() bool {
+	return r.Version == Version && r.Curve.StartSpeed > 0
+}
+
+// Simulate plays the moves through the rules and returns the arena as it
+// was when the snake died or the moves ran out
+func (r *Replay) Simulate() (*rules.Arena, error) {
+	if r.Version != Version {
+		return nil, fmt.Errorf("unsupported replay version %d", r.Version)
+	}
+	if !r.Verifiable() {
+		return nil, fmt.Errorf("mode %q can't be verified", r.Mode)
+	}
+	arena := rules.NewArena(rules.DefaultWidth, rules.DefaultHeight, r.Seed, 1)
+	arena.SetCurve(r.Curve)
+	for move := 0; move < len(r.Moves) && !arena.Over(); move++ {
+		direction := r.Direction(move)
+		if direction == "" {
+			return nil, fmt.Errorf("move %d: bad direction %q", move, r.Moves[move])
+		}
+		arena.SetDirection(0, direction)
+		arena.Step()
+	}
+	return arena, nil
+}
+
+// Verify checks that the claimed score is what the moves actually earn, and
+// that the moves could have been played in the time claimed on the run's curve
+func (r *Replay) Verify() error {
+	if IsCustomMode(r.Mode) && r.Mode != CustomMode(r.Curve) {
+		return fmt.Errorf("mode %q isn't the custom curve's", r.Mode)
+	}
+	arena, err := r.Simulate()
+	if err != nil {
+		return err
+	}
+	if score := arena.Snakes[0].Score; score != r.Score {
+		return fmt.Errorf("claimed %d points but the moves earn %d", r.Score, score)
+	}
+	// The clock is at the least time the moves take, the game counts a bit
+	// more whenever the first move isn't on the first frame
+	if arena.Ramp.Clock.Millis() > (r.Seconds+1)*1000 {
+		return errors.New("too many moves for the time played")
+	}
+	return nil
+}
+
+// Save writes the replay as JSON
+func (r *Replay) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Load reads a replay saved with Save
+func Load(path string) (*Replay, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Replay{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
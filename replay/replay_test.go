@@ -0,0 +1,113 @@
+package replay
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/brantleyr/go-snake/rules"
+)
+
+// chase plays a run that heads straight for each apple until it has eaten
+// apples of them, and claims a generous time for it
+func chase(t *testing.T, seed int64, apples int) *Replay {
+	t.Helper()
+	run := New(ModeNormal, rules.NormalCurve, seed)
+	arena := rules.NewArena(rules.DefaultWidth, rules.DefaultHeight, seed, 1)
+	snake := arena.Snakes[0]
+	for snake.Score < apples {
+		if arena.Over() || arena.Tick > 10000 {
+			t.Fatalf("seed %d: the chase died on %d apples", seed, snake.Score)
+		}
+		head := snake.Head()
+		for _, direction := range []string{rules.Left, rules.Right, rules.Up, rules.Down} {
+			next := arena.Next(head, direction)
+			closer := abs(next.X-arena.Apple.X)+abs(next.Y-arena.Apple.Y) < abs(head.X-arena.Apple.X)+abs(head.Y-arena.Apple.Y)
+			if closer && arena.InBounds(next) && !arena.Occupied(next) {
+				snake.Direction = direction
+				break
+			}
+		}
+		run.Record(snake.Direction)
+		arena.Step()
+	}
+	run.Score = snake.Score
+	run.Seconds = len(run.Moves)
+	return run
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name  string
+		edit  func(run *Replay)
+		error string // part of the error, empty for none
+	}{
+		{"honest run", func(run *Replay) {}, ""},
+		{"died with moves left over", func(run *Replay) { run.Moves += strings.Repeat("L", 30) }, ""},
+		{"score too high", func(run *Replay) { run.Score++ }, "claimed 4 points but the moves earn 3"},
+		{"score too low", func(run *Replay) { run.Score = 0 }, "claimed 0 points but the moves earn 3"},
+		{"too quick", func(run *Replay) { run.Seconds = 1 }, "too many moves"},
+		{"bad direction", func(run *Replay) { run.Moves = "DDX" + run.Moves[3:] }, `move 2: bad direction 'X'`},
+		{"on the easy curve", func(run *Replay) {
+			run.Mode, run.Curve = "easy", rules.Curve{StartSpeed: 24, Step: 2, Interval: 15, Floor: 8, Grace: 3000}
+		}, ""},
+		{"too quick for a slow curve", func(run *Replay) {
+			run.Curve.StartSpeed = 40
+			run.Seconds = len(run.Moves) / 2
+		}, "too many moves"},
+		{"custom curve", func(run *Replay) {
+			run.Curve.Floor = 3
+			run.Mode = CustomMode(run.Curve)
+		}, ""},
+		{"another custom curve's mode", func(run *Replay) {
+			run.Mode = CustomMode(run.Curve)
+			run.Curve.Floor = 3
+		}, "isn't the custom curve's"},
+		{"no curve", func(run *Replay) { run.Mode, run.Curve = "level", rules.Curve{} }, `mode "level" can't be verified`},
+		{"old version", func(run *Replay) { run.Version = 0 }, "unsupported replay version 0"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			run := chase(t, 7, 3)
+			test.edit(run)
+			err := run.Verify()
+			switch {
+			case test.error == "" && err != nil:
+				t.Fatalf("rejected: %v", err)
+			case test.error != "" && err == nil:
+				t.Fatalf("accepted, want %q", test.error)
+			case test.error != "" && !strings.Contains(err.Error(), test.error):
+				t.Fatalf("got %q, want %q", err, test.error)
+			}
+		})
+	}
+}
+
+func TestHashCoversTheRun(t *testing.T) {
+	run := chase(t, 7, 1)
+	hash := run.Hash()
+
+	claimed := *run
+	claimed.Score, claimed.Seconds = 50, 999
+	if claimed.Hash() != hash {
+		t.Error("hash changed with the claimed score and time")
+	}
+	for name, edit := range map[string]func(run *Replay){
+		"seed":  func(run *Replay) { run.Seed++ },
+		"moves": func(run *Replay) { run.Moves += "L" },
+		"mode":  func(run *Replay) { run.Mode = ModeHard },
+		"curve": func(run *Replay) { run.Curve.Grace += 250 },
+	} {
+		changed := *run
+		edit(&changed)
+		if changed.Hash() == hash {
+			t.Errorf("hash didn't change with the %s", name)
+		}
+	}
+}
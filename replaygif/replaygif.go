@@ -0,0 +1,149 @@
+// Package replaygif draws a saved run as an animated GIF with nothing but
+// the standard library, so it works on a server with no display. The run is
+// played back through package rules a frame per move, every move of it, and
+// drawn as flat cells in the game's colors rather than with its sprites.
+package replaygif
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+
+	"github.com/brantleyr/go-snake/replay"
+	"github.com/brantleyr/go-snake/rules"
+	"github.com/brantleyr/go-snake/simclock"
+)
+
+// DefaultCellSize is how many pixels a side each cell gets
+const DefaultCellSize = 16
+
+const centisPerSecond = 100
+
+// The palette is the board's two greens, then the snake and the apple
+var palette = color.Palette{
+	color.RGBA{0x00, 0x22, 0x00, 0xff}, // gridSolidColor
+	color.RGBA{0x00, 0x00, 0x00, 0xff}, // gridAltColor
+	color.RGBA{0x8b, 0xc0, 0x3c, 0xff}, // the snake's body
+	color.RGBA{0xd6, 0xf0, 0xa8, 0xff}, // its head, so it's clear which way it's going
+	color.RGBA{0xff, 0x00, 0x00, 0xff}, // nomColor
+}
+
+const (
+	solidIndex = iota
+	altIndex
+	bodyIndex
+	headIndex
+	appleIndex
+)
+
+// Render plays run back and draws every move, cell pixels to a side. Only
+// the first frame is the whole board, each after it is just the part that
+// changed, so long runs stay small enough to keep in memory.
+func Render(run *replay.Replay, cell int) (*gif.GIF, error) {
+	if !run.Verifiable() {
+		return nil, fmt.Errorf("%s runs can't be played back, only runs on a difficulty", run.Mode)
+	}
+	if cell <= 0 {
+		cell = DefaultCellSize
+	}
+	arena := rules.NewArena(rules.DefaultWidth, rules.DefaultHeight, run.Seed, 1)
+	arena.SetCurve(run.Curve)
+
+	anim := &gif.GIF{Config: image.Config{ColorModel: palette, Width: arena.Width * cell, Height: arena.Height * cell}}
+	var shown []uint8
+	addFrame := func() {
+		board := boardOf(arena)
+		changed := image.Rect(0, 0, arena.Width, arena.Height)
+		if shown != nil {
+			changed = changedCells(shown, board, arena.Width)
+		}
+		shown = board
+		anim.Image = append(anim.Image, drawCells(board, arena.Width, changed, cell))
+		anim.Delay = append(anim.Delay, arena.FramesPerMove()*centisPerSecond/simclock.FramesPerSecond)
+		anim.Disposal = append(anim.Disposal, gif.DisposalNone)
+	}
+	addFrame()
+	for move := 0; move < len(run.Moves) && !arena.Over(); move++ {
+		direction := run.Direction(move)
+		if direction == "" {
+			return nil, fmt.Errorf("move %d: bad direction", move)
+		}
+		arena.SetDirection(0, direction)
+		arena.Step()
+		addFrame()
+	}
+	return anim, nil
+}
+
+// Write renders run to a GIF file at path
+func Write(run *replay.Replay, path string, cell int) error {
+	anim, err := Render(run, cell)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := gif.EncodeAll(file, anim); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// boardOf is the palette index of every cell, a row at a time
+func boardOf(arena *rules.Arena) []uint8 {
+	board := make([]uint8, arena.Width*arena.Height)
+	set := func(at rules.Point, index uint8) {
+		if arena.InBounds(at) {
+			board[at.Y*arena.Width+at.X] = index
+		}
+	}
+	for y := 0; y < arena.Height; y++ {
+		for x := 0; x < arena.Width; x++ {
+			// The same checkerboard as the game's grid
+			if (x+y)%2 == 1 {
+				set(rules.Point{X: x, Y: y}, altIndex)
+			}
+		}
+	}
+	set(arena.Apple, appleIndex)
+	snake := arena.Snakes[0]
+	for idx := len(snake.Body) - 1; idx >= 0; idx-- {
+		index := uint8(bodyIndex)
+		if idx == 0 {
+			index = headIndex
+		}
+		set(snake.Body[idx], index)
+	}
+	return board
+}
+
+// changedCells is the smallest rectangle of cells holding every difference,
+// at least one cell so there's always a frame to hang the delay on
+func changedCells(before []uint8, after []uint8, width int) image.Rectangle {
+	changed := image.Rectangle{}
+	for idx := range after {
+		if before[idx] != after[idx] {
+			changed = changed.Union(image.Rect(idx%width, idx/width, idx%width+1, idx/width+1))
+		}
+	}
+	if changed.Empty() {
+		changed = image.Rect(0, 0, 1, 1)
+	}
+	return changed
+}
+
+// drawCells draws the cells in area, positioned where they go on the board
+func drawCells(board []uint8, width int, area image.Rectangle, cell int) *image.Paletted {
+	frame := image.NewPaletted(image.Rect(area.Min.X*cell, area.Min.Y*cell, area.Max.X*cell, area.Max.Y*cell), palette)
+	for y := frame.Rect.Min.Y; y < frame.Rect.Max.Y; y++ {
+		for x := frame.Rect.Min.X; x < frame.Rect.Max.X; x++ {
+			frame.SetColorIndex(x, y, board[(y/cell)*width+x/cell])
+		}
+	}
+	return frame
+}
@@ -0,0 +1,91 @@
+package replaygif
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brantleyr/go-snake/replay"
+	"github.com/brantleyr/go-snake/rules"
+)
+
+// laps plays a run round the edge of the board, which never ends on its own
+func laps(t *testing.T, seed int64, moves int) (*replay.Replay, *rules.Arena) {
+	t.Helper()
+	run := replay.New(replay.ModeNormal, rules.NormalCurve, seed)
+	arena := rules.NewArena(rules.DefaultWidth, rules.DefaultHeight, seed, 1)
+	snake := arena.Snakes[0]
+	turns := map[string]string{rules.Down: rules.Right, rules.Right: rules.Up, rules.Up: rules.Left, rules.Left: rules.Down}
+	for len(run.Moves) < moves {
+		if arena.Over() {
+			t.Fatalf("seed %d: the laps crashed after %d moves", seed, len(run.Moves))
+		}
+		if !arena.InBounds(arena.Next(snake.Head(), snake.Direction)) {
+			snake.Direction = turns[snake.Direction]
+		}
+		run.Record(snake.Direction)
+		arena.Step()
+	}
+	run.Score = snake.Score
+	return run, arena
+}
+
+func TestRenderKeepsEveryMove(t *testing.T) {
+	run, arena := laps(t, 3, 2500)
+	anim, err := Render(run, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anim.Image) != len(run.Moves)+1 {
+		t.Fatalf("%d frames for %d moves, want a frame per move and the start", len(anim.Image), len(run.Moves))
+	}
+
+	// Written out and read back, the frames drawn over each other end on the board the run ended on
+	path := filepath.Join(t.TempDir(), "run.gif")
+	if err := Write(run, path, 4); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	decoded, err := gif.DecodeAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	screen := image.NewPaletted(image.Rect(0, 0, decoded.Config.Width, decoded.Config.Height), palette)
+	for _, frame := range decoded.Image {
+		draw.Draw(screen, frame.Bounds(), frame, frame.Bounds().Min, draw.Src)
+	}
+	want := drawCells(boardOf(arena), arena.Width, image.Rect(0, 0, arena.Width, arena.Height), 4)
+	if !bytes.Equal(screen.Pix, want.Pix) {
+		t.Error("the last frame isn't the board the run ended on")
+	}
+}
+
+func TestRenderStopsWhereTheRunDied(t *testing.T) {
+	run := replay.New(replay.ModeNormal, rules.NormalCurve, 1)
+	for move := 0; move < 40; move++ {
+		run.Record(rules.Down)
+	}
+	anim, err := Render(run, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The snake starts with its head on row 3 and dies going off the bottom
+	if want := rules.DefaultHeight - 3 + 1; len(anim.Image) != want {
+		t.Errorf("%d frames, want %d", len(anim.Image), want)
+	}
+}
+
+func TestRenderNeedsACurve(t *testing.T) {
+	run := replay.New("shrink", rules.Curve{}, 1)
+	if _, err := Render(run, 2); err == nil {
+		t.Error("a run with no curve to play it back on rendered")
+	}
+}
@@ -0,0 +1,66 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+)
+
+// Profile is a difficulty: the curve a run speeds up on, and the speeds
+// shown where the snake turns orange and red
+type Profile struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Curve
+	OrangeAt int `json:"orange_at"`
+	RedAt    int `json:"red_at"`
+}
+
+// Tier is one of the colors the snake goes as it speeds up
+type Tier struct {
+	Name  string // green, orange or red, which the sprites are named by
+	Color string
+}
+
+var (
+	Green  = Tier{"green", "#8bc03c"}
+	Orange = Tier{"orange", "#ff9300"}
+	Red    = Tier{"red", "#ff3c3c"}
+)
+
+// Tier is the snake's color at the speed shown
+func (p Profile) Tier(shown int) Tier {
+	switch {
+	case shown >= p.RedAt:
+		return Red
+	case shown >= p.OrangeAt:
+		return Orange
+	default:
+		return Green
+	}
+}
+
+// LoadProfiles reads every JSON profile in dir, sorted by file name
+func LoadProfiles(fsys fs.FS, dir string) ([]Profile, error) {
+	paths, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	profiles := []Profile{}
+	for _, profilePath := range paths {
+		src, err := fs.ReadFile(fsys, profilePath)
+		if err != nil {
+			return nil, err
+		}
+		var profile Profile
+		if err := json.Unmarshal(src, &profile); err != nil {
+			return nil, fmt.Errorf("%s: %v", profilePath, err)
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
@@ -0,0 +1,341 @@
+// Package rules is the game of snake without any drawing, sound or input.
+//
+// An Arena holds one or more snakes and an apple, and Step moves every snake
+// one cell at a time. It follows the rules of the Ebiten game so the same
+// moves on the same seed always play out the same way, whether that is on
+// a multiplayer host, a replay check or a headless bot match.
+package rules
+
+import (
+	"math/rand"
+
+	"github.com/brantleyr/go-snake/simclock"
+)
+
+const (
+	DefaultWidth  = 25
+	DefaultHeight = 20
+
+	Up    = "up"
+	Down  = "down"
+	Left  = "left"
+	Right = "right"
+
+	initialBodyCells = 3
+	maxAppleTries    = 1000 // before checking the board isn't full
+)
+
+type Point struct {
+	X, Y int
+}
+
+type Snake struct {
+	ID        int
+	Body      []Point // head first
+	Direction string
+	Alive     bool
+	Score     int
+}
+
+// Head is the first cell of the snake
+func (s *Snake) Head() Point {
+	return s.Body[0]
+}
+
+type Arena struct {
+	Width, Height int
+	Snakes        []*Snake
+	Apple         Point
+	Walls         map[Point]bool
+	Wrap          bool
+	Tick          int
+	Ramp          Ramp // how fast it's going, on a clock that runs the way the game's does
+	ApplesEaten   int
+
+	rand         *rand.Rand
+	initialSnake int
+}
+
+// NewArena sets up a board with the given number of snakes, each in its own
+// corner. A single snake starts exactly where the Ebiten game puts it.
+func NewArena(width int, height int, seed int64, players int) *Arena {
+	a := &Arena{
+		Width:        width,
+		Height:       height,
+		Walls:        map[Point]bool{},
+		rand:         rand.New(rand.NewSource(seed)),
+		initialSnake: players,
+	}
+
+	starts := []struct {
+		x, dy     int
+		fromTop   bool
+		direction string
+	}{
+		{0, 1, true, Down},
+		{width - 1, -1, false, Up},
+		{width - 1, 1, true, Down},
+		{0, -1, false, Up},
+	}
+	for id := 0; id < players && id < len(starts); id++ {
+		start := starts[id]
+		y := 0
+		if !start.fromTop {
+			y = height - 1
+		}
+		body := []Point{}
+		for cell := 0; cell <= initialBodyCells; cell++ {
+			body = append([]Point{{start.x, y + (cell * start.dy)}}, body...)
+		}
+		a.Snakes = append(a.Snakes, &Snake{ID: id, Body: body, Direction: start.direction, Alive: true})
+	}
+
+	a.Ramp.Reset(NormalCurve)
+	a.spawnApple()
+	return a
+}
+
+// SetCurve plays the arena on a difficulty's curve instead of Normal's.
+// Call it before the first Step.
+func (a *Arena) SetCurve(curve Curve) {
+	a.Ramp.Reset(curve)
+}
+
+// Turn changes the direction of a snake. Turning back on itself is ignored,
+// same as the arrow keys in the game. It's checked against the cell behind
+// the head rather than the direction, so two turns before a move can't fold
+// the snake back into its neck.
+func (a *Arena) Turn(id int, direction string) {
+	s := a.Snake(id)
+	if s == nil || !s.Alive {
+		return
+	}
+	switch direction {
+	case Up, Down, Left, Right:
+	default:
+		return
+	}
+	if len(s.Body) > 1 && a.Next(s.Head(), direction) == s.Body[1] {
+		return
+	}
+	s.Direction = direction
+}
+
+// SetDirection points a snake without the no-reversing check Turn makes.
+// Replays use it, since they record the way each move actually went.
+func (a *Arena) SetDirection(id int, direction string) {
+	if s := a.Snake(id); s != nil && s.Alive {
+		s.Direction = direction
+	}
+}
+
+// Snake finds a snake by ID
+func (a *Arena) Snake(id int) *Snake {
+	for _, s := range a.Snakes {
+		if s.ID == id {
+			return s
+		}
+	}
+	return nil
+}
+
+// Alive counts the snakes still playing
+func (a *Arena) Alive() int {
+	alive := 0
+	for _, s := range a.Snakes {
+		if s.Alive {
+			alive++
+		}
+	}
+	return alive
+}
+
+// Over reports whether the game has finished. A solo game ends when the
+// snake dies, a multiplayer game when one snake or none is left.
+func (a *Arena) Over() bool {
+	if a.initialSnake > 1 {
+		return a.Alive() <= 1
+	}
+	return a.Alive() == 0
+}
+
+// FramesPerMove is how many 60Hz frames pass between moves at the current speed
+func (a *Arena) FramesPerMove() int {
+	return a.Ramp.Speed + 1
+}
+
+// MoveInterval is FramesPerMove in milliseconds
+func (a *Arena) MoveInterval() int {
+	return a.FramesPerMove() * 1000 / simclock.FramesPerSecond
+}
+
+// Rand is where the arena's apples come from. A live game can carry on
+// from a simulated one by drawing from it.
+func (a *Arena) Rand() *rand.Rand {
+	return a.rand
+}
+
+// Next returns the cell one step from p in the given direction
+func (a *Arena) Next(p Point, direction string) Point {
+	switch direction {
+	case Up:
+		p.Y--
+	case Down:
+		p.Y++
+	case Left:
+		p.X--
+	case Right:
+		p.X++
+	}
+	if a.Wrap {
+		p.X = (p.X + a.Width) % a.Width
+		p.Y = (p.Y + a.Height) % a.Height
+	}
+	return p
+}
+
+// InBounds reports whether the point is on the board
+func (a *Arena) InBounds(p Point) bool {
+	return p.X >= 0 && p.Y >= 0 && p.X < a.Width && p.Y < a.Height
+}
+
+// Occupied reports whether a snake or a wall is on the point
+func (a *Arena) Occupied(p Point) bool {
+	if a.Walls[p] {
+		return true
+	}
+	for _, s := range a.Snakes {
+		if !s.Alive {
+			continue
+		}
+		for _, cell := range s.Body {
+			if cell == p {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Step moves every live snake one cell, then works out who ate and who crashed
+func (a *Arena) Step() {
+	if a.Over() {
+		return
+	}
+	a.wait()
+	a.Tick++
+
+	// Move everyone at once. Like the original game, the tail only gets out
+	// of the way after the move, so running into the cell it is leaving is a hit.
+	eaters := []*Snake{}
+	vacated := map[Point]bool{}
+	for _, s := range a.Snakes {
+		if !s.Alive {
+			continue
+		}
+		head := a.Next(s.Head(), s.Direction)
+		s.Body = append([]Point{head}, s.Body...)
+		if head == a.Apple {
+			eaters = append(eaters, s)
+		} else {
+			vacated[s.Body[len(s.Body)-1]] = true
+			s.Body = s.Body[:len(s.Body)-1]
+		}
+	}
+
+	// Work out the crashes before anyone is removed, so head on collisions take out both
+	crashed := []*Snake{}
+	for _, s := range a.Snakes {
+		if s.Alive && a.crashed(s, vacated) {
+			crashed = append(crashed, s)
+		}
+	}
+	for _, s := range crashed {
+		s.Alive = false
+	}
+
+	ateApple := false
+	for _, s := range eaters {
+		if s.Alive {
+			s.Score++
+			ateApple = true
+		}
+	}
+	if ateApple {
+		a.ApplesEaten++
+		a.Ramp.Ate(a.ApplesEaten)
+		a.spawnApple()
+	}
+}
+
+// wait runs the clock up to the frame of the next move. Like the game, the
+// first move is on the first frame and each one after takes the speed's
+// frames plus one, checking the speed as each frame goes by since a speed
+// up can land in between.
+func (a *Arena) wait() {
+	if a.Tick > 0 {
+		for waited := 1; ; waited++ {
+			a.Ramp.Clock.Tick()
+			if waited >= a.Ramp.Speed {
+				break
+			}
+		}
+	}
+	a.Ramp.Clock.Tick()
+}
+
+func (a *Arena) crashed(s *Snake, vacated map[Point]bool) bool {
+	head := s.Head()
+	if !a.InBounds(head) || a.Walls[head] || vacated[head] {
+		return true
+	}
+	for _, other := range a.Snakes {
+		if !other.Alive {
+			continue
+		}
+		for idx, cell := range other.Body {
+			if other == s && idx == 0 {
+				continue
+			}
+			if cell == head {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// spawnApple picks random cells until it finds a free one. Like the original
+// game it never picks the last row or column. If the snake has filled every
+// cell an apple could go in there is no apple, and it sits off the board.
+func (a *Arena) spawnApple() {
+	for tries := 0; ; tries++ {
+		p := Point{a.rand.Intn(a.Width - 1), a.rand.Intn(a.Height - 1)}
+		if !a.Occupied(p) {
+			a.Apple = p
+			return
+		}
+		if tries == maxAppleTries && !a.hasFreeAppleCell() {
+			a.Apple = Point{-1, -1}
+			return
+		}
+	}
+}
+
+func (a *Arena) hasFreeAppleCell() bool {
+	for x := 0; x < a.Width-1; x++ {
+		for y := 0; y < a.Height-1; y++ {
+			if !a.Occupied(Point{x, y}) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Remove takes a snake out of the game, for a player who left
+func (a *Arena) Remove(id int) {
+	if s := a.Snake(id); s != nil {
+		s.Alive = false
+	}
+}
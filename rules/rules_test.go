@@ -0,0 +1,37 @@
+package rules
+
+import "testing"
+
+func TestTurnCantFoldIntoNeck(t *testing.T) {
+	a := NewArena(DefaultWidth, DefaultHeight, 1, 1)
+	s := a.Snake(0) // heading down from the top left
+	a.Turn(0, Right)
+	a.Turn(0, Up)
+	if s.Direction != Right {
+		t.Fatalf("heading %s after right then up, want right", s.Direction)
+	}
+	a.Step()
+	if !s.Alive {
+		t.Fatal("snake died turning right")
+	}
+	a.Turn(0, Up)
+	if s.Direction != Up {
+		t.Fatalf("heading %s after a move then up, want up", s.Direction)
+	}
+}
+
+// Moves take as long as they do in the game: the first on the first frame,
+// then the speed's frames plus one each
+func TestArenaClock(t *testing.T) {
+	a := NewArena(DefaultWidth, DefaultHeight, 1, 1)
+	a.SetCurve(Curve{StartSpeed: 12, Step: 2, Interval: 10, Floor: 5, Grace: 1000})
+	for move := 0; move < 10; move++ {
+		a.Step()
+	}
+	if frames := a.Ramp.Clock.Frames(); frames != 1+9*13 {
+		t.Fatalf("10 moves took %d frames, want %d", frames, 1+9*13)
+	}
+	if a.MoveInterval() != 13*1000/60 {
+		t.Fatalf("move interval %dms, want %dms", a.MoveInterval(), 13*1000/60)
+	}
+}
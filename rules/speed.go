@@ -0,0 +1,81 @@
+package rules
+
+import "github.com/brantleyr/go-snake/simclock"
+
+// Curve is how a run speeds up, the numbers from a difficulty profile.
+// Speeds are frames between moves, so lower is faster.
+type Curve struct {
+	StartSpeed int `json:"start_speed"`
+	Step       int `json:"step"`     // frames taken off each speed up
+	Interval   int `json:"interval"` // apples between speed ups
+	Floor      int `json:"floor"`    // never faster than this
+	Grace      int `json:"grace"`    // milliseconds to react after eating before a speed up kicks in
+}
+
+// NormalCurve is the Normal profile, which an arena plays on unless it's
+// given another
+var NormalCurve = Curve{StartSpeed: 20, Step: 2, Interval: 10, Floor: 5, Grace: 2000}
+
+// Ramp is how fast a run is going. Speed ups are events on the run's clock,
+// so whoever ticks the clock owns the ramp as well, and two runs never
+// share anything.
+type Ramp struct {
+	Curve Curve
+	Clock simclock.Clock
+	Speed int // frames between moves
+	Shown int // the speed shown to the player, 1 and up
+
+	due []int // the frames queued speed ups land on, so Restore can queue them again
+}
+
+// Reset starts the ramp over on curve, dropping speed ups still to come
+func (r *Ramp) Reset(curve Curve) {
+	r.Curve = curve
+	r.Clock.Reset()
+	r.Speed = curve.StartSpeed
+	r.Shown = 1
+	r.due = nil
+}
+
+// Restore makes r the ramp from, speed ups still to come and all. Those
+// are queued again on r's own clock rather than shared with from's.
+func (r *Ramp) Restore(from *Ramp) {
+	r.Reset(from.Curve)
+	r.Speed = from.Speed
+	r.Shown = from.Shown
+	r.Clock.SetFrames(from.Clock.Frames())
+	for _, at := range from.due {
+		r.queue(at - r.Clock.Frames())
+	}
+}
+
+// Ate queues a speed up every Interval apples, Grace after the apple that earned it
+func (r *Ramp) Ate(score int) {
+	interval := r.Curve.Interval
+	if interval <= 0 || score < interval || score%interval != 0 {
+		return
+	}
+	r.queue(simclock.FramesIn(r.Curve.Grace))
+}
+
+func (r *Ramp) queue(frames int) {
+	r.due = append(r.due, r.Clock.Frames()+frames)
+	r.Clock.After(frames, func() {
+		// Every speed up waits the same grace, so they land in the order they were queued
+		r.due = r.due[1:]
+		r.speedUp()
+	})
+}
+
+func (r *Ramp) speedUp() {
+	r.Speed -= r.Curve.Step
+	// The speed shown goes up one for every two frames
+	if r.Curve.Step > 1 {
+		r.Shown += r.Curve.Step / 2
+	} else {
+		r.Shown++
+	}
+	if r.Speed < r.Curve.Floor {
+		r.Speed = r.Curve.Floor
+	}
+}
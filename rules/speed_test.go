@@ -0,0 +1,239 @@
+package rules
+
+import (
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/brantleyr/go-snake/simclock"
+)
+
+// The Hard profile from assets/difficulty
+var hardCurve = Curve{StartSpeed: 20, Step: 4, Interval: 10, Floor: 4, Grace: 2000}
+
+// play ticks the ramp's clock frame by frame, eating an apple every
+// eatEvery frames the way the game does
+func play(r *Ramp, frames int, eatEvery int, score *int) {
+	for frame := 1; frame <= frames; frame++ {
+		r.Clock.Tick()
+		if frame%eatEvery == 0 {
+			*score++
+			r.Ate(*score)
+		}
+	}
+}
+
+func TestRampWaitsOutTheGrace(t *testing.T) {
+	var r Ramp
+	r.Reset(NormalCurve)
+	for score := 1; score < 10; score++ {
+		r.Ate(score)
+	}
+	if r.Clock.Pending() != 0 {
+		t.Fatalf("%d speed ups queued before the tenth apple", r.Clock.Pending())
+	}
+	r.Ate(10)
+	for frame := 1; frame < simclock.FramesIn(2000); frame++ {
+		r.Clock.Tick()
+	}
+	if r.Speed != 20 || r.Shown != 1 {
+		t.Fatalf("sped up to %d/%d inside the grace", r.Speed, r.Shown)
+	}
+	r.Clock.Tick()
+	if r.Speed != 18 || r.Shown != 2 {
+		t.Fatalf("at %d/%d after the grace, want 18/2", r.Speed, r.Shown)
+	}
+}
+
+func TestRampShowsTwoFramesAsOne(t *testing.T) {
+	var r Ramp
+	r.Reset(hardCurve)
+	r.speedUp()
+	if r.Speed != 16 || r.Shown != 3 {
+		t.Fatalf("hard speed up went to %d/%d, want 16/3", r.Speed, r.Shown)
+	}
+}
+
+func TestRampStaysAboveFloor(t *testing.T) {
+	var r Ramp
+	r.Reset(hardCurve)
+	score := 0
+	play(&r, simclock.FramesIn(120000), 10, &score)
+	if r.Speed != 4 {
+		t.Fatalf("speed %d, want the floor of 4", r.Speed)
+	}
+	if r.Shown < 10 {
+		t.Fatalf("shown speed %d, want it to keep counting up", r.Shown)
+	}
+}
+
+// Nothing from the last run may speed up the next one
+func TestRampResetDropsSpeedUps(t *testing.T) {
+	var r Ramp
+	r.Reset(NormalCurve)
+	r.Ate(10)
+	r.Reset(NormalCurve)
+	for frame := 0; frame < simclock.FramesIn(5000); frame++ {
+		r.Clock.Tick()
+	}
+	if r.Speed != 20 || r.Shown != 1 {
+		t.Fatalf("at %d/%d, a speed up from before the reset fired", r.Speed, r.Shown)
+	}
+}
+
+// A ramp restored part way through still speeds up when the one it came from would have
+func TestRampRestoreKeepsSpeedUps(t *testing.T) {
+	var from Ramp
+	from.Reset(hardCurve)
+	score := 0
+	play(&from, simclock.FramesIn(30000), 30, &score)
+	if from.Clock.Pending() == 0 {
+		t.Fatal("nothing queued to restore, eat closer to the end")
+	}
+
+	var r Ramp
+	r.Restore(&from)
+	if r.Speed != from.Speed || r.Shown != from.Shown || r.Clock.Frames() != from.Clock.Frames() {
+		t.Fatalf("restored at %d/%d on frame %d, want %d/%d on frame %d",
+			r.Speed, r.Shown, r.Clock.Frames(), from.Speed, from.Shown, from.Clock.Frames())
+	}
+	if r.Clock.Pending() != from.Clock.Pending() {
+		t.Fatalf("%d speed ups queued, want %d", r.Clock.Pending(), from.Clock.Pending())
+	}
+	for frame := 0; frame < simclock.FramesIn(hardCurve.Grace); frame++ {
+		from.Clock.Tick()
+		r.Clock.Tick()
+		if r.Speed != from.Speed || r.Shown != from.Shown {
+			t.Fatalf("frame %d: restored ramp at %d/%d, want %d/%d", r.Clock.Frames(), r.Speed, r.Shown, from.Speed, from.Shown)
+		}
+	}
+	if r.Clock.Pending() != 0 {
+		t.Errorf("%d speed ups still queued after the grace", r.Clock.Pending())
+	}
+}
+
+// chase turns the snake towards the apple, or anywhere it won't crash
+// straight away when it can't get any closer
+func chase(a *Arena) {
+	s := a.Snakes[0]
+	head := s.Head()
+	distance := func(p Point) int { return abs(p.X-a.Apple.X) + abs(p.Y-a.Apple.Y) }
+	safe := ""
+	for _, direction := range []string{Left, Right, Up, Down} {
+		next := a.Next(head, direction)
+		if !a.InBounds(next) || a.Occupied(next) {
+			continue
+		}
+		if distance(next) < distance(head) {
+			a.Turn(s.ID, direction)
+			return
+		}
+		if safe == "" {
+			safe = direction
+		}
+	}
+	if safe != "" {
+		a.Turn(s.ID, safe)
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// race plays an arena the way its owners do, a turn and then a Step that
+// ticks the arena's own clock through the frames until the move
+func race(a *Arena, ticks int) {
+	for !a.Over() && a.Tick < ticks {
+		chase(a)
+		a.Step()
+	}
+}
+
+// Two arenas in one process, like a host's match next to a bot tournament,
+// must never move each other's speed
+func TestArenasShareSpeedWithNobody(t *testing.T) {
+	normal := NewArena(DefaultWidth, DefaultHeight, 5, 1)
+	hard := NewArena(DefaultWidth, DefaultHeight, 5, 1)
+	hard.SetCurve(hardCurve)
+	for hard.Ramp.Shown == 1 {
+		if hard.Over() {
+			t.Fatalf("the hard run died on %d apples before it sped up", hard.ApplesEaten)
+		}
+		chase(hard)
+		hard.Step()
+	}
+	if normal.Ramp.Speed != NormalCurve.StartSpeed || normal.Ramp.Shown != 1 || normal.Ramp.Clock.Frames() != 0 {
+		t.Fatalf("the untouched arena is at %d/%d on frame %d after the other sped up",
+			normal.Ramp.Speed, normal.Ramp.Shown, normal.Ramp.Clock.Frames())
+	}
+}
+
+// Run with -race: arenas played at once end where they end played alone
+func TestArenasRunTogether(t *testing.T) {
+	curves := []Curve{NormalCurve, hardCurve}
+	arenas := make([]*Arena, 8)
+	var wg sync.WaitGroup
+	for idx := range arenas {
+		arenas[idx] = NewArena(DefaultWidth, DefaultHeight, int64(idx), 1)
+		arenas[idx].SetCurve(curves[idx%len(curves)])
+		wg.Add(1)
+		go func(a *Arena) {
+			defer wg.Done()
+			race(a, 3000)
+		}(arenas[idx])
+	}
+	wg.Wait()
+
+	for idx, a := range arenas {
+		alone := NewArena(DefaultWidth, DefaultHeight, int64(idx), 1)
+		alone.SetCurve(curves[idx%len(curves)])
+		race(alone, 3000)
+		if a.Ramp.Speed != alone.Ramp.Speed || a.Ramp.Shown != alone.Ramp.Shown || a.Ramp.Clock.Frames() != alone.Ramp.Clock.Frames() || a.ApplesEaten != alone.ApplesEaten {
+			t.Errorf("arena %d ended at %d/%d on frame %d with %d apples, played alone at %d/%d on frame %d with %d", idx,
+				a.Ramp.Speed, a.Ramp.Shown, a.Ramp.Clock.Frames(), a.ApplesEaten,
+				alone.Ramp.Speed, alone.Ramp.Shown, alone.Ramp.Clock.Frames(), alone.ApplesEaten)
+		}
+	}
+}
+
+func TestProfileTiers(t *testing.T) {
+	profile := Profile{OrangeAt: 4, RedAt: 7}
+	tests := []struct {
+		shown int
+		want  Tier
+	}{
+		{1, Green},
+		{3, Green},
+		{4, Orange},
+		{6, Orange},
+		{7, Red},
+		{20, Red},
+	}
+	for _, test := range tests {
+		if got := profile.Tier(test.shown); got != test.want {
+			t.Errorf("speed %d is %s, want %s", test.shown, got.Name, test.want.Name)
+		}
+	}
+}
+
+// The curves here and in the tests are the profiles the game ships with
+func TestCurvesMatchTheProfiles(t *testing.T) {
+	profiles, err := LoadProfiles(os.DirFS("../assets"), "difficulty")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]Curve{"normal": NormalCurve, "hard": hardCurve}
+	for _, profile := range profiles {
+		if curve, ok := want[profile.ID]; ok && profile.Curve != curve {
+			t.Errorf("%s is %+v in assets, %+v here", profile.ID, profile.Curve, curve)
+		}
+		delete(want, profile.ID)
+	}
+	if len(want) != 0 {
+		t.Errorf("no profiles for %v", want)
+	}
+}
@@ -0,0 +1,79 @@
+// Package simclock is the game's clock. It counts simulation frames rather
+// than wall time, so it stops whenever the simulation does, and it runs
+// events scheduled on it from the same goroutine that ticks it.
+//
+// A Clock is not safe for concurrent use. It doesn't need to be: whoever
+// runs the simulation owns the clock and everything its events touch.
+package simclock
+
+import "sort"
+
+// FramesPerSecond is how many frames make a second of game time
+const FramesPerSecond = 60
+
+type event struct {
+	at  int
+	run func()
+}
+
+type Clock struct {
+	frames int
+	events []event // in the order they fire
+}
+
+// Reset goes back to zero and drops anything still scheduled, so nothing
+// from the last run can fire in the next one
+func (c *Clock) Reset() {
+	c.frames = 0
+	c.events = nil
+}
+
+// Tick moves the clock on a frame and runs the events that are due
+func (c *Clock) Tick() {
+	c.frames++
+	for len(c.events) > 0 && c.events[0].at <= c.frames {
+		next := c.events[0]
+		c.events = c.events[1:]
+		next.run()
+	}
+}
+
+// After schedules run for the given number of frames from now. Events due
+// on the same frame run in the order they were scheduled.
+func (c *Clock) After(frames int, run func()) {
+	at := c.frames + frames
+	idx := sort.Search(len(c.events), func(i int) bool {
+		return c.events[i].at > at
+	})
+	c.events = append(c.events, event{})
+	copy(c.events[idx+1:], c.events[idx:])
+	c.events[idx] = event{at, run}
+}
+
+// Pending is how many events haven't fired yet
+func (c *Clock) Pending() int {
+	return len(c.events)
+}
+
+func (c *Clock) Frames() int {
+	return c.frames
+}
+
+// SetFrames moves the clock to a time saved earlier. Scheduled events keep
+// the frame they were due on.
+func (c *Clock) SetFrames(frames int) {
+	c.frames = frames
+}
+
+func (c *Clock) Millis() int {
+	return c.frames * 1000 / FramesPerSecond
+}
+
+func (c *Clock) Seconds() int {
+	return c.frames / FramesPerSecond
+}
+
+// FramesIn converts a duration in milliseconds to frames
+func FramesIn(millis int) int {
+	return millis * FramesPerSecond / 1000
+}
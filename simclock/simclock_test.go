@@ -0,0 +1,116 @@
+package simclock
+
+import "testing"
+
+func TestEventRunsWhenDue(t *testing.T) {
+	var c Clock
+	fired := 0
+	c.After(3, func() { fired++ })
+	for frame := 1; frame <= 2; frame++ {
+		c.Tick()
+		if fired != 0 {
+			t.Fatalf("fired on frame %d, want frame 3", frame)
+		}
+	}
+	c.Tick()
+	if fired != 1 {
+		t.Fatalf("fired %d times on frame 3, want once", fired)
+	}
+	c.Tick()
+	if fired != 1 || c.Pending() != 0 {
+		t.Fatalf("fired %d times with %d pending, want once and none", fired, c.Pending())
+	}
+}
+
+func TestEventsRunInOrder(t *testing.T) {
+	var c Clock
+	order := []string{}
+	c.After(5, func() { order = append(order, "late") })
+	c.After(2, func() { order = append(order, "early") })
+	c.After(5, func() { order = append(order, "late again") })
+	c.After(2, func() { order = append(order, "early again") })
+	for frame := 0; frame < 5; frame++ {
+		c.Tick()
+	}
+	want := []string{"early", "early again", "late", "late again"}
+	if len(order) != len(want) {
+		t.Fatalf("got %v, want %v", order, want)
+	}
+	for idx := range want {
+		if order[idx] != want[idx] {
+			t.Fatalf("got %v, want %v", order, want)
+		}
+	}
+}
+
+func TestEventCanSchedule(t *testing.T) {
+	var c Clock
+	fired := []int{}
+	var again func()
+	again = func() {
+		fired = append(fired, c.Frames())
+		if len(fired) < 3 {
+			c.After(2, again)
+		}
+	}
+	c.After(1, again)
+	for frame := 0; frame < 10; frame++ {
+		c.Tick()
+	}
+	if len(fired) != 3 || fired[0] != 1 || fired[1] != 3 || fired[2] != 5 {
+		t.Fatalf("fired on frames %v, want [1 3 5]", fired)
+	}
+}
+
+// A paused game stops ticking, and so stops its events along with the time
+func TestPausedClockHoldsEvents(t *testing.T) {
+	var c Clock
+	fired := false
+	c.After(FramesIn(2000), func() { fired = true })
+	for frame := 0; frame < FramesIn(1000); frame++ {
+		c.Tick()
+	}
+	// Paused for however long, nothing ticks
+	if fired || c.Seconds() != 1 {
+		t.Fatalf("fired %t at %d seconds, want nothing at 1", fired, c.Seconds())
+	}
+	for frame := 0; frame < FramesIn(1000); frame++ {
+		c.Tick()
+	}
+	if !fired || c.Millis() != 2000 {
+		t.Fatalf("fired %t at %dms, want fired at 2000ms", fired, c.Millis())
+	}
+}
+
+// Nothing from the last run may fire in the next one
+func TestResetDropsEvents(t *testing.T) {
+	var c Clock
+	fired := false
+	c.After(10, func() { fired = true })
+	c.Tick()
+	c.Reset()
+	if c.Frames() != 0 || c.Pending() != 0 {
+		t.Fatalf("at frame %d with %d pending after a reset, want 0 and 0", c.Frames(), c.Pending())
+	}
+	for frame := 0; frame < 20; frame++ {
+		c.Tick()
+	}
+	if fired {
+		t.Fatal("an event from before the reset fired")
+	}
+}
+
+func TestSetFramesKeepsEvents(t *testing.T) {
+	var c Clock
+	fired := false
+	c.After(100, func() { fired = true })
+	c.SetFrames(98)
+	c.Tick()
+	if fired {
+		t.Fatal("fired a frame early")
+	}
+	c.Tick()
+	if !fired {
+		t.Fatal("didn't fire on its frame")
+	}
+}
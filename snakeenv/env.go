@@ -0,0 +1,214 @@
+// Package snakeenv wraps the rules in a reinforcement learning environment.
+//
+// It follows the gym shape: Reset starts an episode and returns the first
+// observation, Step takes an action and returns the next observation, the
+// reward, whether the episode is done and some extra info. Observations are
+// the board as a stack of grids. Serve speaks the same API as JSON lines so
+// trainers in other languages can run the environment as a subprocess.
+package snakeenv
+
+import (
+	"github.com/brantleyr/go-snake/rules"
+)
+
+// Actions are absolute directions. Asking the snake to turn back on itself
+// does nothing, same as in the game.
+const (
+	ActionUp = iota
+	ActionDown
+	ActionLeft
+	ActionRight
+	NumActions
+)
+
+// Channels of an observation, each a Height x Width grid of 0s and 1s
+const (
+	ChannelBody = iota
+	ChannelHead
+	ChannelApple
+	ChannelWall
+	NumChannels
+)
+
+var actionDirections = [NumActions]string{rules.Up, rules.Down, rules.Left, rules.Right}
+
+// Rewards shape what the agent is paid for. Closer and Farther are paid when
+// a move brings the head nearer to or further from the apple, by Manhattan distance.
+type Rewards struct {
+	Apple   float64 `json:"apple"`
+	Death   float64 `json:"death"`
+	Step    float64 `json:"step"`
+	Closer  float64 `json:"closer"`
+	Farther float64 `json:"farther"`
+}
+
+type Config struct {
+	Width   int     `json:"width"`
+	Height  int     `json:"height"`
+	Wrap    bool    `json:"wrap"`
+	Rewards Rewards `json:"rewards"`
+	// Episodes are cut short after this many steps, or this many steps without
+	// an apple, so an agent going round in circles doesn't run forever. 0 is no limit.
+	MaxSteps       int `json:"max_steps"`
+	MaxHungrySteps int `json:"max_hungry_steps"`
+}
+
+// DefaultConfig is the board from the game, paid for apples and charged for dying
+func DefaultConfig() Config {
+	return Config{
+		Width:          rules.DefaultWidth,
+		Height:         rules.DefaultHeight,
+		Rewards:        Rewards{Apple: 1, Death: -1},
+		MaxHungrySteps: rules.DefaultWidth * rules.DefaultHeight,
+	}
+}
+
+// Observation is a Channels x Height x Width tensor, stored flat in that order
+type Observation struct {
+	Shape [3]int    `json:"shape"`
+	Data  []float32 `json:"data"`
+}
+
+// At reads one cell of one channel
+func (o Observation) At(channel int, y int, x int) float32 {
+	return o.Data[(channel*o.Shape[1]+y)*o.Shape[2]+x]
+}
+
+type Info struct {
+	Score  int  `json:"score"`
+	Length int  `json:"length"`
+	Steps  int  `json:"steps"`
+	Ate    bool `json:"ate"`
+	// Truncated is set when the episode hit a step limit rather than the snake dying
+	Truncated bool   `json:"truncated"`
+	Death     string `json:"death,omitempty"` // wall or self
+}
+
+type Env struct {
+	Config Config
+	arena  *rules.Arena
+	steps  int
+	hungry int
+	done   bool
+}
+
+// New makes an environment, call Reset before stepping it
+func New(config Config) *Env {
+	if config.Width <= 1 || config.Height <= 1 {
+		config.Width, config.Height = rules.DefaultWidth, rules.DefaultHeight
+	}
+	return &Env{Config: config, done: true}
+}
+
+// Reset starts a new episode. The same seed always deals the same apples.
+func (e *Env) Reset(seed int64) Observation {
+	e.arena = rules.NewArena(e.Config.Width, e.Config.Height, seed, 1)
+	e.arena.Wrap = e.Config.Wrap
+	e.steps = 0
+	e.hungry = 0
+	e.done = false
+	return e.observe()
+}
+
+// Step moves the snake one cell. Stepping a finished episode changes nothing
+// and reports done again.
+func (e *Env) Step(action int) (Observation, float64, bool, Info) {
+	if e.done {
+		return e.observe(), 0, true, e.info(false)
+	}
+	snake := e.arena.Snakes[0]
+	if action >= 0 && action < NumActions {
+		e.arena.Turn(snake.ID, actionDirections[action])
+	}
+
+	before := distance(snake.Head(), e.arena.Apple)
+	score := snake.Score
+	e.arena.Step()
+	e.steps++
+	e.hungry++
+
+	rewards := e.Config.Rewards
+	reward := rewards.Step
+	ate := snake.Score > score
+	switch {
+	case !snake.Alive:
+		reward += rewards.Death
+		e.done = true
+	case ate:
+		reward += rewards.Apple
+		e.hungry = 0
+	default:
+		after := distance(snake.Head(), e.arena.Apple)
+		if after < before {
+			reward += rewards.Closer
+		} else if after > before {
+			reward += rewards.Farther
+		}
+	}
+
+	truncated := false
+	if !e.done && ((e.Config.MaxSteps > 0 && e.steps >= e.Config.MaxSteps) ||
+		(e.Config.MaxHungrySteps > 0 && e.hungry >= e.Config.MaxHungrySteps)) {
+		truncated = true
+		e.done = true
+	}
+
+	info := e.info(ate)
+	info.Truncated = truncated
+	return e.observe(), reward, e.done, info
+}
+
+// Arena is the game underneath, for drawing or debugging. Don't step it directly.
+func (e *Env) Arena() *rules.Arena {
+	return e.arena
+}
+
+func (e *Env) info(ate bool) Info {
+	snake := e.arena.Snakes[0]
+	info := Info{Score: snake.Score, Length: len(snake.Body), Steps: e.steps, Ate: ate}
+	if !snake.Alive {
+		info.Death = "self"
+		if !e.arena.InBounds(snake.Head()) || e.arena.Walls[snake.Head()] {
+			info.Death = "wall"
+		}
+	}
+	return info
+}
+
+func (e *Env) observe() Observation {
+	width, height := e.arena.Width, e.arena.Height
+	obs := Observation{
+		Shape: [3]int{NumChannels, height, width},
+		Data:  make([]float32, NumChannels*height*width),
+	}
+	set := func(channel int, p rules.Point) {
+		if e.arena.InBounds(p) {
+			obs.Data[(channel*height+p.Y)*width+p.X] = 1
+		}
+	}
+
+	snake := e.arena.Snakes[0]
+	for idx, cell := range snake.Body {
+		if idx == 0 {
+			set(ChannelHead, cell)
+		} else {
+			set(ChannelBody, cell)
+		}
+	}
+	set(ChannelApple, e.arena.Apple)
+	for wall := range e.arena.Walls {
+		set(ChannelWall, wall)
+	}
+	return obs
+}
+
+func distance(a rules.Point, b rules.Point) int {
+	dx, dy := a.X-b.X, a.Y-b.Y
+	if dx < 0 {
+		dx = -dx
+	}
+	if dy < 0 {
+		dy = -dy
+	}
+	return dx + dy
+}
@@ -0,0 +1,165 @@
+package snakeenv
+
+import (
+	"testing"
+
+	"github.com/brantleyr/go-snake/rules"
+)
+
+// The snake starts in the top left corner heading down, head on (0, 3)
+var startHead = rules.Point{X: 0, Y: 3}
+
+func count(obs Observation, channel int) int {
+	cells := 0
+	for y := 0; y < obs.Shape[1]; y++ {
+		for x := 0; x < obs.Shape[2]; x++ {
+			if obs.At(channel, y, x) == 1 {
+				cells++
+			}
+		}
+	}
+	return cells
+}
+
+func TestReset(t *testing.T) {
+	env := New(DefaultConfig())
+	obs := env.Reset(7)
+
+	want := [3]int{NumChannels, rules.DefaultHeight, rules.DefaultWidth}
+	if obs.Shape != want || len(obs.Data) != NumChannels*rules.DefaultHeight*rules.DefaultWidth {
+		t.Fatalf("shape %v with %d values, want %v", obs.Shape, len(obs.Data), want)
+	}
+	if obs.At(ChannelHead, startHead.Y, startHead.X) != 1 || count(obs, ChannelHead) != 1 {
+		t.Error("head isn't alone on its start cell")
+	}
+	if body := count(obs, ChannelBody); body != 3 {
+		t.Errorf("%d body cells, want 3", body)
+	}
+	apple := env.Arena().Apple
+	if obs.At(ChannelApple, apple.Y, apple.X) != 1 || count(obs, ChannelApple) != 1 {
+		t.Error("apple isn't alone on its cell")
+	}
+	if count(obs, ChannelWall) != 0 {
+		t.Error("walls on an open board")
+	}
+
+	// The same seed deals the same apple, whatever happened before
+	env.Step(ActionRight)
+	env.Step(ActionRight)
+	env.Reset(7)
+	if env.Arena().Apple != apple || env.Arena().Tick != 0 {
+		t.Errorf("reset gave apple %v on tick %d, want %v on tick 0", env.Arena().Apple, env.Arena().Tick, apple)
+	}
+}
+
+func TestStepIntoWall(t *testing.T) {
+	env := New(DefaultConfig())
+	env.Reset(7)
+
+	_, reward, done, info := env.Step(ActionLeft)
+	if !done || reward != -1 || info.Death != "wall" || info.Truncated {
+		t.Fatalf("left off the board gave reward %v, done %v, info %+v", reward, done, info)
+	}
+	// A finished episode stays finished
+	_, reward, done, info = env.Step(ActionRight)
+	if !done || reward != 0 || info.Steps != 1 {
+		t.Fatalf("stepping after the end gave reward %v, done %v, info %+v", reward, done, info)
+	}
+}
+
+func TestStepCantReverse(t *testing.T) {
+	env := New(DefaultConfig())
+	env.Reset(7)
+	obs, _, done, _ := env.Step(ActionUp)
+	if done || obs.At(ChannelHead, startHead.Y+1, startHead.X) != 1 {
+		t.Fatal("turning back on the neck wasn't ignored")
+	}
+}
+
+func TestRewardShaping(t *testing.T) {
+	config := DefaultConfig()
+	config.Rewards = Rewards{Apple: 10, Death: -10, Step: -0.5, Closer: 1, Farther: -2}
+	env := New(config)
+	env.Reset(8)
+	if apple := env.Arena().Apple; apple != (rules.Point{X: 16, Y: 0}) {
+		t.Fatalf("seed 8 dealt the apple on %v, the moves below expect (16, 0)", apple)
+	}
+
+	// One down, away from the apple, then right and up onto it
+	type move struct {
+		action int
+		reward float64
+	}
+	moves := []move{{ActionDown, -0.5 - 2}}
+	for x := 1; x <= 16; x++ {
+		moves = append(moves, move{ActionRight, -0.5 + 1})
+	}
+	for y := 3; y >= 1; y-- {
+		moves = append(moves, move{ActionUp, -0.5 + 1})
+	}
+	moves = append(moves, move{ActionUp, -0.5 + 10})
+	for idx, move := range moves {
+		_, reward, done, info := env.Step(move.action)
+		if done || reward != move.reward {
+			t.Fatalf("move %d paid %v, done %v, want %v", idx, reward, done, move.reward)
+		}
+		if info.Ate != (idx == len(moves)-1) {
+			t.Fatalf("move %d ate %v", idx, info.Ate)
+		}
+	}
+	if info := env.info(false); info.Score != 1 || info.Length != 5 {
+		t.Fatalf("after the apple %+v, want score 1 and length 5", info)
+	}
+
+	// Straight up into the top
+	_, reward, done, _ := env.Step(ActionUp)
+	if !done || reward != -0.5-10 {
+		t.Fatalf("dying paid %v, done %v, want %v", reward, done, -0.5-10)
+	}
+}
+
+func TestStepLimits(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		config func(c *Config)
+	}{
+		{"max steps", func(c *Config) { c.MaxSteps = 3 }},
+		{"max hungry steps", func(c *Config) { c.MaxHungrySteps = 3 }},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			config := DefaultConfig()
+			test.config(&config)
+			env := New(config)
+			env.Reset(7) // the apple is well out of the way
+			for step := 1; step <= 3; step++ {
+				_, _, done, info := env.Step(ActionDown)
+				if done != (step == 3) || info.Truncated != (step == 3) {
+					t.Fatalf("step %d: done %v, truncated %v", step, done, info.Truncated)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkStep(b *testing.B) {
+	env := New(DefaultConfig())
+	env.Reset(1)
+	// Circle the board's edge so the snake lives as long as it can
+	turns := map[rules.Point]int{
+		{X: 0, Y: rules.DefaultHeight - 1}:                      ActionRight,
+		{X: rules.DefaultWidth - 1, Y: rules.DefaultHeight - 1}: ActionUp,
+		{X: rules.DefaultWidth - 1, Y: 0}:                       ActionLeft,
+		{X: 0, Y: 0}:                                            ActionDown,
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		action, ok := turns[env.Arena().Snakes[0].Head()]
+		if !ok {
+			action = ActionDown
+		}
+		if _, _, done, _ := env.Step(action); done {
+			env.Reset(int64(i))
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package snakeenv
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+// Request is one line from the trainer:
+//
+//	{"cmd":"config","config":{"rewards":{"apple":1,"death":-1,"closer":0.01}}}
+//	{"cmd":"reset","seed":7}
+//	{"cmd":"step","action":3}
+//	{"cmd":"close"}
+//
+// A config request replaces the whole config, starting from DefaultConfig,
+// and takes effect on the next reset.
+type Request struct {
+	Cmd    string  `json:"cmd"`
+	Seed   int64   `json:"seed"`
+	Action int     `json:"action"`
+	Config *Config `json:"config,omitempty"`
+}
+
+// Response is one line back. Reset only fills in the observation.
+type Response struct {
+	Observation *Observation `json:"observation,omitempty"`
+	Reward      float64      `json:"reward"`
+	Done        bool         `json:"done"`
+	Info        *Info        `json:"info,omitempty"`
+	Error       string       `json:"error,omitempty"`
+}
+
+// Serve runs an environment driven by JSON lines on r, answering each line on w,
+// until it reads a close request or r runs out
+func Serve(r io.Reader, w io.Writer) error {
+	env := New(DefaultConfig())
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	out := bufio.NewWriter(w)
+	enc := json.NewEncoder(out)
+
+	for scanner.Scan() {
+		// Config fields the trainer leaves out keep their defaults
+		config := DefaultConfig()
+		req := Request{Config: &config}
+		var resp Response
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			resp.Error = "bad request: " + err.Error()
+		} else {
+			switch req.Cmd {
+			case "config":
+				env = New(config)
+			case "reset":
+				obs := env.Reset(req.Seed)
+				resp.Observation = &obs
+			case "step":
+				if env.arena == nil {
+					resp.Error = "reset before stepping"
+					break
+				}
+				obs, reward, done, info := env.Step(req.Action)
+				resp = Response{Observation: &obs, Reward: reward, Done: done, Info: &info}
+			case "close":
+				return out.Flush()
+			default:
+				resp.Error = "unknown cmd " + req.Cmd
+			}
+		}
+		if err := enc.Encode(resp); err != nil {
+			return err
+		}
+		// The trainer waits for every answer, so don't sit on it
+		if err := out.Flush(); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
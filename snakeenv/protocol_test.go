@@ -0,0 +1,77 @@
+package snakeenv
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestServeRoundTrip(t *testing.T) {
+	requests := []string{
+		`{"cmd":"step","action":1}`,
+		`{"cmd":"config","config":{"rewards":{"closer":0.25}}}`,
+		`{"cmd":"reset","seed":8}`,
+		`{"cmd":"step","action":3}`,
+		`{"cmd":"step","action":2}`,
+		`not json`,
+		`{"cmd":"fly"}`,
+		`{"cmd":"close"}`,
+		`{"cmd":"reset","seed":1}`,
+	}
+	var out bytes.Buffer
+	if err := Serve(strings.NewReader(strings.Join(requests, "\n")+"\n"), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	var responses []Response
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var resp Response
+		if err := dec.Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		responses = append(responses, resp)
+	}
+	// Close answers nothing and nothing after it is read
+	if len(responses) != 7 {
+		t.Fatalf("%d responses, want 7", len(responses))
+	}
+
+	if responses[0].Error != "reset before stepping" {
+		t.Errorf("stepping first got %+v", responses[0])
+	}
+	if responses[1].Error != "" || responses[1].Observation != nil {
+		t.Errorf("config got %+v", responses[1])
+	}
+
+	// The same seed and actions on an Env give the same answers. Left isn't
+	// a turn while heading right, so the snake keeps going that way.
+	config := DefaultConfig()
+	config.Rewards.Closer = 0.25 // the rest keep their defaults
+	env := New(config)
+	obs := env.Reset(8)
+	if !reflect.DeepEqual(*responses[2].Observation, obs) || responses[2].Info != nil {
+		t.Errorf("reset didn't send the first observation")
+	}
+	for idx, action := range []int{ActionRight, ActionLeft} {
+		resp := responses[3+idx]
+		obs, reward, done, info := env.Step(action)
+		want := Response{Observation: &obs, Reward: reward, Done: done, Info: &info}
+		if !reflect.DeepEqual(resp, want) {
+			t.Errorf("step %d got reward %v, done %v, info %+v, want %v, %v, %+v",
+				idx, resp.Reward, resp.Done, resp.Info, reward, done, info)
+		}
+		if reward != 0.25 {
+			t.Errorf("step %d paid %v, want the config's 0.25 for getting closer", idx, reward)
+		}
+	}
+
+	if !strings.HasPrefix(responses[5].Error, "bad request: ") {
+		t.Errorf("bad JSON got %+v", responses[5])
+	}
+	if responses[6].Error != "unknown cmd fly" {
+		t.Errorf("unknown cmd got %+v", responses[6])
+	}
+}
@@ -0,0 +1,157 @@
+// Package spectate streams a running game to browsers.
+//
+// Server serves a small canvas viewer on / and pushes every Frame the game
+// publishes to /ws as JSON. It never blocks the game: a viewer that falls
+// behind just skips frames.
+package spectate
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+)
+
+//go:embed viewer.html
+var viewerHTML []byte
+
+type Point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Frame is one snapshot of the game, as seen by spectators
+type Frame struct {
+	Mode      string  `json:"mode"`
+	Status    string  `json:"status"` // waiting, playing, paused, over
+	Width     int     `json:"width"`
+	Height    int     `json:"height"`
+	Snake     []Point `json:"snake"` // head first
+	Direction string  `json:"direction"`
+	Apple     *Point  `json:"apple,omitempty"`
+	Score     int     `json:"score"`
+	Speed     int     `json:"speed"`
+	Elapsed   int     `json:"elapsed"` // seconds
+}
+
+type Server struct {
+	listener net.Listener
+	mu       sync.Mutex
+	viewers  map[chan []byte]bool
+	latest   []byte
+}
+
+// Listen starts serving spectators on addr, for example ":8080"
+func Listen(addr string) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{listener: listener, viewers: map[chan []byte]bool{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.serveViewer)
+	mux.HandleFunc("/ws", s.serveStream)
+	go func() {
+		// Close stops it, that's not worth a log line
+		if err := http.Serve(listener, mux); err != nil && !errors.Is(err, net.ErrClosed) {
+			log.Println("spectate:", err)
+		}
+	}()
+	return s, nil
+}
+
+// Addr is the address the server ended up listening on
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *Server) Close() error {
+	return s.listener.Close()
+}
+
+// Publish sends a frame to every viewer. It is safe to call every tick.
+func (s *Server) Publish(frame Frame) {
+	data, err := json.Marshal(frame)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if string(data) == string(s.latest) {
+		return
+	}
+	s.latest = data
+	for viewer := range s.viewers {
+		select {
+		case viewer <- data:
+		default:
+			// Viewer is behind, drop the stale frame and queue this one
+			select {
+			case <-viewer:
+			default:
+			}
+			viewer <- data
+		}
+	}
+}
+
+func (s *Server) serveViewer(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(viewerHTML)
+}
+
+func (s *Server) serveStream(w http.ResponseWriter, r *http.Request) {
+	ws, err := upgrade(w, r)
+	if err != nil {
+		return
+	}
+	defer ws.Close()
+
+	frames := make(chan []byte, 1)
+	s.mu.Lock()
+	s.viewers[frames] = true
+	if s.latest != nil {
+		frames <- s.latest
+	}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.viewers, frames)
+		s.mu.Unlock()
+	}()
+
+	// Watch for the browser closing the tab
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		for {
+			opcode, payload, err := ws.readFrame()
+			if err != nil || opcode == opClose {
+				return
+			}
+			if opcode == opPing {
+				ws.writeFrame(opPong, payload)
+			}
+		}
+	}()
+
+	for {
+		select {
+		case data := <-frames:
+			if err := ws.writeFrame(opText, data); err != nil {
+				return
+			}
+		case <-gone:
+			return
+		}
+	}
+}
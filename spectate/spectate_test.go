@@ -0,0 +1,193 @@
+package spectate
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// The handshake example from RFC 6455 section 1.3
+const (
+	exampleKey    = "dGhlIHNhbXBsZSBub25jZQ=="
+	exampleAccept = "s3pPLMBiTxaQ9kYGzzhZRbK+xOo="
+)
+
+// dial opens /ws the way a browser does, and gives back the connection
+// ready to read the server's frames with
+func dial(t *testing.T, s *Server) *wsConn {
+	t.Helper()
+	conn, err := net.Dial("tcp", s.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	request := "GET /ws HTTP/1.1\r\nHost: " + s.Addr() + "\r\nUpgrade: websocket\r\nConnection: keep-alive, Upgrade\r\n" +
+		"Sec-WebSocket-Key: " + exampleKey + "\r\nSec-WebSocket-Version: 13\r\n\r\n"
+	if _, err := conn.Write([]byte(request)); err != nil {
+		t.Fatal(err)
+	}
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("upgrade got %s", response.Status)
+	}
+	if accept := response.Header.Get("Sec-WebSocket-Accept"); accept != exampleAccept {
+		t.Fatalf("Sec-WebSocket-Accept is %q, want %q", accept, exampleAccept)
+	}
+	return &wsConn{conn: conn, rw: bufio.NewReadWriter(reader, bufio.NewWriter(conn))}
+}
+
+// clientFrame is a frame the way a browser sends it, always masked
+func clientFrame(opcode byte, payload []byte, mask [4]byte) []byte {
+	frame := []byte{0x80 | opcode}
+	switch {
+	case len(payload) < 126:
+		frame = append(frame, 0x80|byte(len(payload)))
+	case len(payload) <= 0xffff:
+		frame = append(frame, 0x80|126, 0, 0)
+		binary.BigEndian.PutUint16(frame[2:], uint16(len(payload)))
+	default:
+		frame = append(frame, 0x80|127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(frame[2:], uint64(len(payload)))
+	}
+	frame = append(frame, mask[:]...)
+	for idx, b := range payload {
+		frame = append(frame, b^mask[idx%4])
+	}
+	return frame
+}
+
+func listen(t *testing.T) *Server {
+	t.Helper()
+	s, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestViewerSeesPublishedFrames(t *testing.T) {
+	s := listen(t)
+	viewer := dial(t, s)
+
+	want := Frame{Mode: "Normal", Status: "playing", Width: 25, Height: 20, Snake: []Point{{3, 4}, {3, 5}},
+		Direction: "up", Apple: &Point{10, 10}, Score: 2, Speed: 1, Elapsed: 7}
+	s.Publish(want)
+
+	opcode, payload, err := viewer.readFrame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opcode != opText {
+		t.Fatalf("opcode %#x, want a text frame", opcode)
+	}
+	var got Frame
+	if err := json.Unmarshal(payload, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("viewer got %+v, want %+v", got, want)
+	}
+}
+
+func TestPingGetsPong(t *testing.T) {
+	s := listen(t)
+	viewer := dial(t, s)
+
+	if _, err := viewer.conn.Write(clientFrame(opPing, []byte("still there?"), [4]byte{1, 2, 3, 4})); err != nil {
+		t.Fatal(err)
+	}
+	opcode, payload, err := viewer.readFrame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opcode != opPong || string(payload) != "still there?" {
+		t.Errorf("got opcode %#x %q, want a pong with the ping's payload", opcode, payload)
+	}
+}
+
+func TestPlainRequestIsRefused(t *testing.T) {
+	s := listen(t)
+	response, err := http.Get("http://" + s.Addr() + "/ws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusBadRequest {
+		t.Errorf("a request without an upgrade got %s", response.Status)
+	}
+}
+
+// Lengths on each side of where the 7 bit, 16 bit and 64 bit lengths take over
+func TestFrameLengths(t *testing.T) {
+	tests := []struct {
+		length int
+		header []byte // the server's header for it, up to the payload
+	}{
+		{0, []byte{0x81, 0}},
+		{125, []byte{0x81, 125}},
+		{126, []byte{0x81, 126, 0, 126}},
+		{0xffff, []byte{0x81, 126, 0xff, 0xff}},
+		{0x10000, []byte{0x81, 127, 0, 0, 0, 0, 0, 1, 0, 0}},
+	}
+	for _, test := range tests {
+		payload := bytes.Repeat([]byte{'x'}, test.length)
+
+		// Written by the server: the right length encoding and no mask
+		server, client := net.Pipe()
+		ws := &wsConn{conn: server, rw: bufio.NewReadWriter(bufio.NewReader(server), bufio.NewWriter(server))}
+		go func() {
+			ws.writeFrame(opText, payload)
+			server.Close()
+		}()
+		written, err := io.ReadAll(client)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasPrefix(written, test.header) || len(written) != len(test.header)+test.length {
+			t.Errorf("%d bytes: server wrote a header of % x, want % x", test.length, written[:len(test.header)], test.header)
+		}
+
+		// Read by the server: masked the way a browser does it
+		server, client = net.Pipe()
+		ws = &wsConn{conn: server, rw: bufio.NewReadWriter(bufio.NewReader(server), bufio.NewWriter(server))}
+		go func() {
+			client.Write(clientFrame(opText, payload, [4]byte{0xde, 0xad, 0xbe, 0xef}))
+			client.Close()
+		}()
+		opcode, read, err := ws.readFrame()
+		if err != nil {
+			t.Fatalf("%d bytes: %v", test.length, err)
+		}
+		if opcode != opText || !bytes.Equal(read, payload) {
+			t.Errorf("%d bytes: read back opcode %#x and %d bytes that don't match", test.length, opcode, len(read))
+		}
+		server.Close()
+	}
+}
+
+func TestOversizedFrameIsRefused(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+	ws := &wsConn{conn: server, rw: bufio.NewReadWriter(bufio.NewReader(server), bufio.NewWriter(server))}
+	go func() {
+		client.Write(clientFrame(opText, make([]byte, maxReadFrame+1), [4]byte{}))
+		client.Close()
+	}()
+	if _, _, err := ws.readFrame(); err == nil {
+		t.Error("a frame over the limit was read")
+	}
+}
@@ -0,0 +1,34 @@
+//go:build !js
+
+package storage
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// FileStore saves each key as a file in Dir
+type FileStore struct {
+	Dir string
+}
+
+// Default is a FileStore in the go-snake config directory, or the current
+// directory if the system doesn't have one
+func Default() Store {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return FileStore{"."}
+	}
+	return FileStore{filepath.Join(dir, "go-snake")}
+}
+
+func (s FileStore) Load(key string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(s.Dir, key))
+}
+
+func (s FileStore) Save(key string, data []byte) error {
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.Dir, key), data, 0o644)
+}
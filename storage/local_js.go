@@ -0,0 +1,45 @@
+//go:build js
+
+package storage
+
+import (
+	"errors"
+	"syscall/js"
+)
+
+const keyPrefix = "go-snake/"
+
+// LocalStore saves each key in the browser's localStorage
+type LocalStore struct{}
+
+// Default is localStorage, the only place a page can keep anything
+func Default() Store {
+	return LocalStore{}
+}
+
+func (LocalStore) Load(key string) ([]byte, error) {
+	storage := js.Global().Get("localStorage")
+	if !storage.Truthy() {
+		return nil, errors.New("localStorage isn't available")
+	}
+	value := storage.Call("getItem", keyPrefix+key)
+	if value.IsNull() {
+		return nil, ErrNotFound
+	}
+	return []byte(value.String()), nil
+}
+
+func (LocalStore) Save(key string, data []byte) (err error) {
+	storage := js.Global().Get("localStorage")
+	if !storage.Truthy() {
+		return errors.New("localStorage isn't available")
+	}
+	// setItem throws when the quota is used up
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("localStorage is full")
+		}
+	}()
+	storage.Call("setItem", keyPrefix+key, string(data))
+	return nil
+}
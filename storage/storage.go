@@ -0,0 +1,14 @@
+// Package storage keeps small bits of saved data, like settings and high
+// scores, by key. On desktops they are files in the user's config directory;
+// in a browser they live in localStorage.
+package storage
+
+import "io/fs"
+
+// ErrNotFound is returned by Load for a key that was never saved
+var ErrNotFound = fs.ErrNotExist
+
+type Store interface {
+	Load(key string) ([]byte, error)
+	Save(key string, data []byte) error
+}
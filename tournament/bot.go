@@ -0,0 +1,151 @@
+package tournament
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Bot is a program that plays snake over stdin and stdout
+type Bot struct {
+	Name    string
+	Command []string
+}
+
+// ParseBots turns command lines like "python3 bots/greedy.py" into bots,
+// named after the program and numbered if two share a name
+func ParseBots(commands []string) []Bot {
+	bots := []Bot{}
+	seen := map[string]int{}
+	for _, command := range commands {
+		fields := strings.Fields(command)
+		if len(fields) == 0 {
+			continue
+		}
+		name := filepath.Base(fields[len(fields)-1])
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+		seen[name]++
+		if seen[name] > 1 {
+			name += "-" + strconv.Itoa(seen[name])
+		}
+		bots = append(bots, Bot{Name: name, Command: fields})
+	}
+	return bots
+}
+
+// process is one running copy of a bot. Each match gets fresh processes so
+// nothing a bot remembers can leak from one match into the next.
+type process struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	enc   *json.Encoder
+	lines chan string
+	quit  chan struct{} // closed by stop, so the reader never waits on lines for good
+	stuck bool          // stopped reading its stdin, it gets nothing more
+}
+
+func startBot(bot Bot) (*process, error) {
+	cmd := exec.Command(bot.Command[0], bot.Command[1:]...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	p := &process{
+		cmd:   cmd,
+		stdin: stdin,
+		enc:   json.NewEncoder(stdin),
+		lines: make(chan string, 16),
+		quit:  make(chan struct{}),
+	}
+	go func() {
+		defer close(p.lines)
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			// A chatty bot can fill lines when nobody is asking any more
+			select {
+			case p.lines <- strings.TrimSpace(scanner.Text()):
+			case <-p.quit:
+				return
+			}
+		}
+	}()
+	return p, nil
+}
+
+// send writes one message. A bot that has died just stops getting them, and
+// one that doesn't take it within timeout is stuck and never gets another,
+// so a bot that stops reading can't hold up the match. The write left
+// waiting ends when stop closes the pipe.
+func (p *process) send(msg message, timeout time.Duration) {
+	if p.stuck {
+		return
+	}
+	written := make(chan struct{})
+	go func() {
+		p.enc.Encode(msg)
+		close(written)
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-written:
+	case <-timer.C:
+		p.stuck = true
+	}
+}
+
+// reply waits until the deadline for the bot's move on tick, "" if it has
+// none. Answers for earlier ticks came in too late and are thrown away.
+func (p *process) reply(tick int, deadline time.Time) string {
+	wait := time.Until(deadline)
+	if wait < 0 {
+		wait = 0
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for {
+		select {
+		case line, ok := <-p.lines:
+			if !ok {
+				return ""
+			}
+			fields := strings.Fields(line)
+			if len(fields) == 2 && fields[0] == strconv.Itoa(tick) {
+				return fields[1]
+			}
+		case <-timer.C:
+			return ""
+		}
+	}
+}
+
+func (p *process) stop() {
+	close(p.quit)
+	p.stdin.Close()
+	done := make(chan struct{})
+	go func() {
+		p.cmd.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		p.cmd.Process.Kill()
+		<-done
+	}
+}
@@ -0,0 +1,165 @@
+package tournament
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The test binary doubles as the bots: run with scripted-bot and a script
+// name, it plays that script instead of the tests
+func TestMain(m *testing.M) {
+	if len(os.Args) == 3 && os.Args[1] == "scripted-bot" {
+		playScript(os.Args[2])
+		os.Exit(0)
+	}
+	// Under -race each bot would otherwise wait a second on its way out
+	os.Setenv("GORACE", "atexit_sleep_ms=0")
+	os.Exit(m.Run())
+}
+
+func scriptedBot(script string) Bot {
+	return Bot{Name: script, Command: []string{os.Args[0], "scripted-bot", script}}
+}
+
+var steps = map[string]point{"up": {0, -1}, "down": {0, 1}, "left": {-1, 0}, "right": {1, 0}}
+
+// playScript answers every state the same way each time: straight keeps
+// going, safe takes the first move that doesn't crash straight away, and
+// deaf never reads anything
+func playScript(script string) {
+	if script == "deaf" {
+		select {}
+	}
+	var width, height int
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var msg message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			continue
+		}
+		switch msg.Type {
+		case "start":
+			width, height = msg.Width, msg.Height
+		case "state":
+			me := msg.Snakes[msg.You]
+			move := me.Direction
+			if script == "safe" {
+				move = safeMove(msg, me, width, height)
+			}
+			fmt.Println(msg.Tick, move)
+		}
+	}
+}
+
+func safeMove(msg message, me snakeView, width int, height int) string {
+	taken := map[point]bool{}
+	for _, snake := range msg.Snakes {
+		for _, cell := range snake.Body {
+			taken[cell] = true
+		}
+	}
+	head := me.Body[0]
+	for _, move := range []string{me.Direction, "up", "right", "down", "left"} {
+		next := point{head.X + steps[move].X, head.Y + steps[move].Y}
+		if next.X >= 0 && next.Y >= 0 && next.X < width && next.Y < height && !taken[next] {
+			return move
+		}
+	}
+	return me.Direction
+}
+
+func TestScriptedBotsGiveTheSameResults(t *testing.T) {
+	tournament := func() []Standing {
+		opts := DefaultOptions()
+		opts.MaxTicks = 300
+		opts.Timeout = 5 * time.Second // they answer straight away, this is for slow CI machines
+		tour := &Tournament{Bots: []Bot{scriptedBot("straight"), scriptedBot("safe")}, Games: 2, Seed: 7, Options: opts}
+		standings, err := tour.RoundRobin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return standings
+	}
+
+	first := tournament()
+	if first[0].Bot != "safe" || first[0].Points <= first[1].Points {
+		t.Errorf("safe should come out on top of a bot that drives into the wall, got %+v", first)
+	}
+	if first[0].Played != 4 || first[1].Played != 4 {
+		t.Errorf("each bot should play both corners of both seeds, got %+v", first)
+	}
+	if again := tournament(); !reflect.DeepEqual(first, again) {
+		t.Errorf("the same seed gave different results:\n%+v\n%+v", first, again)
+	}
+}
+
+func TestLateAnswersDontCount(t *testing.T) {
+	p := &process{lines: make(chan string, 4)}
+	p.lines <- "3 up"
+	p.lines <- "nonsense"
+	p.lines <- "4 left"
+	if move := p.reply(4, time.Now().Add(time.Second)); move != "left" {
+		t.Errorf("tick 4 got %q, want left", move)
+	}
+	p.lines <- "4 down"
+	if move := p.reply(5, time.Now().Add(10*time.Millisecond)); move != "" {
+		t.Errorf("tick 5 took %q, which was meant for tick 4", move)
+	}
+}
+
+func TestBotThatStopsReadingIsLeftBehind(t *testing.T) {
+	p, err := startBot(scriptedBot("deaf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.stop()
+
+	// Far more than a pipe holds, so the write can't finish
+	big := message{Type: "state", Snakes: []snakeView{{Direction: strings.Repeat("x", 1<<20)}}}
+	started := time.Now()
+	p.send(big, 100*time.Millisecond)
+	if waited := time.Since(started); waited > 2*time.Second {
+		t.Fatalf("send waited %v on a bot that doesn't read", waited)
+	}
+	if !p.stuck {
+		t.Fatal("a bot that stopped reading should be marked stuck")
+	}
+	started = time.Now()
+	p.send(message{Type: "state"}, time.Second)
+	if waited := time.Since(started); waited > 100*time.Millisecond {
+		t.Errorf("a stuck bot was sent to again, waited %v", waited)
+	}
+}
+
+func TestStopEndsTheReader(t *testing.T) {
+	if _, err := exec.LookPath("yes"); err != nil {
+		t.Skip("needs yes to play a bot that never stops talking")
+	}
+	before := runtime.NumGoroutine()
+	p, err := startBot(Bot{Name: "yes", Command: []string{"yes"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Let it fill lines with nobody reading them
+	for len(p.lines) < cap(p.lines) {
+		time.Sleep(time.Millisecond)
+	}
+	p.stop()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines left after stop, started with %d", runtime.NumGoroutine(), before)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
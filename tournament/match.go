@@ -0,0 +1,175 @@
+package tournament
+
+import (
+	"errors"
+	"time"
+
+	"github.com/brantleyr/go-snake/replay"
+	"github.com/brantleyr/go-snake/rules"
+)
+
+// The bot protocol is one JSON message per line on the bot's stdin:
+//
+//	{"type":"start","you":0,"seed":1,"width":25,"height":20,"players":2}
+//	{"type":"state","you":0,"tick":0,"apple":{"x":4,"y":9},"snakes":[...]}
+//	{"type":"end","you":0,"winner":1}
+//
+// and the bot answers every state with one line on stdout, the state's tick
+// and then up, down, left or right: "12 left". Anything else, or no answer in
+// time, keeps the snake going straight, and an answer for an earlier tick
+// doesn't count for this one. Snakes can't turn back on themselves, same as
+// in the game.
+type message struct {
+	Type    string      `json:"type"`
+	You     int         `json:"you"`
+	Seed    int64       `json:"seed,omitempty"`
+	Width   int         `json:"width,omitempty"`
+	Height  int         `json:"height,omitempty"`
+	Players int         `json:"players,omitempty"`
+	Tick    int         `json:"tick"`
+	Apple   *point      `json:"apple,omitempty"`
+	Snakes  []snakeView `json:"snakes,omitempty"`
+	Winner  *int        `json:"winner,omitempty"` // -1 for a draw
+}
+
+type point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type snakeView struct {
+	ID        int     `json:"id"`
+	Body      []point `json:"body"` // head first
+	Direction string  `json:"direction"`
+	Alive     bool    `json:"alive"`
+	Score     int     `json:"score"`
+}
+
+type Options struct {
+	Width, Height int
+	MaxTicks      int           // a match still going after this many ticks goes to the highest score
+	Timeout       time.Duration // how long bots get to answer each tick
+}
+
+// DefaultOptions plays on the game's board with half a second a move
+func DefaultOptions() Options {
+	return Options{
+		Width:    rules.DefaultWidth,
+		Height:   rules.DefaultHeight,
+		MaxTicks: 2000,
+		Timeout:  500 * time.Millisecond,
+	}
+}
+
+// Outcome is how a match went for each bot, in snake ID order
+type Outcome struct {
+	Replay *replay.Match
+	Drawn  []bool // still in it at the end of a match nobody won
+}
+
+// PlayMatch runs one match between the bots, the first bot playing snake 0
+func PlayMatch(bots []Bot, seed int64, opts Options) (*Outcome, error) {
+	if len(bots) < 2 || len(bots) > 4 {
+		return nil, errors.New("a match needs 2 to 4 bots")
+	}
+	names := []string{}
+	procs := []*process{}
+	defer func() {
+		for _, p := range procs {
+			p.stop()
+		}
+	}()
+	for id, bot := range bots {
+		p, err := startBot(bot)
+		if err != nil {
+			return nil, err
+		}
+		procs = append(procs, p)
+		names = append(names, bot.Name)
+		p.send(message{Type: "start", You: id, Seed: seed, Width: opts.Width, Height: opts.Height, Players: len(bots)}, opts.Timeout)
+	}
+
+	arena := rules.NewArena(opts.Width, opts.Height, seed, len(bots))
+	match := replay.NewMatch(seed, opts.Width, opts.Height, names)
+	aliveBefore := make([]bool, len(bots))
+	for !arena.Over() && arena.Tick < opts.MaxTicks {
+		// Everyone sees the same board and thinks at the same time
+		state := viewOf(arena)
+		for _, s := range arena.Snakes {
+			if s.Alive {
+				state.You = s.ID
+				procs[s.ID].send(state, opts.Timeout)
+			}
+		}
+		deadline := time.Now().Add(opts.Timeout)
+		for _, s := range arena.Snakes {
+			aliveBefore[s.ID] = s.Alive
+			if !s.Alive {
+				continue
+			}
+			arena.Turn(s.ID, procs[s.ID].reply(state.Tick, deadline))
+			match.Record(s.ID, s.Direction)
+		}
+		arena.Step()
+	}
+
+	outcome := &Outcome{Replay: match, Drawn: make([]bool, len(bots))}
+	for _, s := range arena.Snakes {
+		match.Scores = append(match.Scores, s.Score)
+	}
+	match.Winner = winner(arena, aliveBefore, outcome.Drawn)
+
+	for id, p := range procs {
+		end := message{Type: "end", You: id, Tick: arena.Tick, Winner: &match.Winner}
+		p.send(end, opts.Timeout)
+	}
+	return outcome, nil
+}
+
+// winner is the last snake standing or, at the tick limit, the best scorer
+// still alive. With no winner, drawn marks the snakes that lasted to the end.
+func winner(arena *rules.Arena, aliveBefore []bool, drawn []bool) int {
+	alive := []*rules.Snake{}
+	for _, s := range arena.Snakes {
+		if s.Alive {
+			alive = append(alive, s)
+		}
+	}
+	if len(alive) == 0 {
+		// They went out together on the last tick
+		for id, was := range aliveBefore {
+			drawn[id] = was
+		}
+		return -1
+	}
+
+	best := []*rules.Snake{alive[0]}
+	for _, s := range alive[1:] {
+		if s.Score > best[0].Score {
+			best = []*rules.Snake{s}
+		} else if s.Score == best[0].Score {
+			best = append(best, s)
+		}
+	}
+	if len(best) == 1 {
+		return best[0].ID
+	}
+	for _, s := range best {
+		drawn[s.ID] = true
+	}
+	return -1
+}
+
+func viewOf(arena *rules.Arena) message {
+	msg := message{Type: "state", Tick: arena.Tick, Apple: &point{arena.Apple.X, arena.Apple.Y}}
+	for _, s := range arena.Snakes {
+		view := snakeView{ID: s.ID, Direction: s.Direction, Alive: s.Alive, Score: s.Score}
+		if s.Alive {
+			for _, cell := range s.Body {
+				view.Body = append(view.Body, point{cell.X, cell.Y})
+			}
+		}
+		msg.Snakes = append(msg.Snakes, view)
+	}
+	return msg
+}
@@ -0,0 +1,156 @@
+// Package tournament runs bot programs against each other on the rules.
+//
+// Bots are separate processes speaking a line protocol (see PlayMatch), so
+// they can be written in anything. Every match is played from a fixed seed
+// and saved as a replay, so a tournament can be run again and checked.
+package tournament
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+)
+
+const (
+	pointsWin  = 3
+	pointsDraw = 1
+)
+
+type Standing struct {
+	Bot    string
+	Played int
+	Wins   int
+	Draws  int
+	Losses int
+	Points int
+	Apples int
+}
+
+type Tournament struct {
+	Bots      []Bot
+	Games     int   // per pairing in a round robin, in total in a free for all
+	Seed      int64 // game n is played on Seed+n
+	Options   Options
+	ReplayDir string    // where replays go, nothing is saved if empty
+	Log       io.Writer // a line per match, if set
+}
+
+// RoundRobin plays every pair of bots against each other. Each seed is
+// played twice with the corners swapped so neither bot gets the better start.
+func (t *Tournament) RoundRobin() ([]Standing, error) {
+	table := t.newTable()
+	for game := 0; game < t.Games; game++ {
+		for i := 0; i < len(t.Bots); i++ {
+			for j := i + 1; j < len(t.Bots); j++ {
+				for _, order := range [][]int{{i, j}, {j, i}} {
+					if err := t.play(table, order, t.Seed+int64(game)); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+	}
+	return rank(table), nil
+}
+
+// FreeForAll puts every bot in the same arena, up to four, taking turns at
+// each corner from game to game
+func (t *Tournament) FreeForAll() ([]Standing, error) {
+	if len(t.Bots) > 4 {
+		return nil, fmt.Errorf("a free for all takes at most 4 bots, got %d", len(t.Bots))
+	}
+	table := t.newTable()
+	for game := 0; game < t.Games; game++ {
+		order := []int{}
+		for idx := range t.Bots {
+			order = append(order, (idx+game)%len(t.Bots))
+		}
+		if err := t.play(table, order, t.Seed+int64(game)); err != nil {
+			return nil, err
+		}
+	}
+	return rank(table), nil
+}
+
+func (t *Tournament) newTable() []Standing {
+	table := []Standing{}
+	for _, bot := range t.Bots {
+		table = append(table, Standing{Bot: bot.Name})
+	}
+	if t.ReplayDir != "" {
+		os.MkdirAll(t.ReplayDir, 0o755)
+	}
+	return table
+}
+
+// play runs one match, order being which bot plays which snake
+func (t *Tournament) play(table []Standing, order []int, seed int64) error {
+	bots := []Bot{}
+	for _, idx := range order {
+		bots = append(bots, t.Bots[idx])
+	}
+	outcome, err := PlayMatch(bots, seed, t.Options)
+	if err != nil {
+		return err
+	}
+	match := outcome.Replay
+
+	for id, idx := range order {
+		standing := &table[idx]
+		standing.Played++
+		standing.Apples += match.Scores[id]
+		switch {
+		case match.Winner == id:
+			standing.Wins++
+			standing.Points += pointsWin
+		case outcome.Drawn[id]:
+			standing.Draws++
+			standing.Points += pointsDraw
+		default:
+			standing.Losses++
+		}
+	}
+
+	result := "draw"
+	if match.Winner >= 0 {
+		result = match.Players[match.Winner] + " wins"
+	}
+	if t.Log != nil {
+		fmt.Fprintf(t.Log, "seed %d: %v %v, %s\n", seed, match.Players, match.Scores, result)
+	}
+	if t.ReplayDir == "" {
+		return nil
+	}
+	name := fmt.Sprintf("seed%d", seed)
+	for _, player := range match.Players {
+		name += "-" + player
+	}
+	return match.Save(filepath.Join(t.ReplayDir, name+".json"))
+}
+
+// rank sorts by points, then wins, then apples eaten
+func rank(table []Standing) []Standing {
+	sort.SliceStable(table, func(i, j int) bool {
+		if table[i].Points != table[j].Points {
+			return table[i].Points > table[j].Points
+		}
+		if table[i].Wins != table[j].Wins {
+			return table[i].Wins > table[j].Wins
+		}
+		return table[i].Apples > table[j].Apples
+	})
+	return table
+}
+
+// WriteTable prints the standings as a text table
+func WriteTable(w io.Writer, standings []Standing) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "#\tBot\tPlayed\tWon\tDrawn\tLost\tApples\tPoints\t")
+	for idx, s := range standings {
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%d\t%d\t%d\t%d\t\n", idx+1, s.Bot, s.Played, s.Wins, s.Draws, s.Losses, s.Apples, s.Points)
+	}
+	return tw.Flush()
+}
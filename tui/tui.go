@@ -0,0 +1,261 @@
+// Package tui plays snake in a terminal with ANSI escapes.
+//
+// It runs the same rules as the Ebiten game, a cell being two columns wide
+// so the board keeps its shape, and colors the snake by the same green,
+// orange and red speed tiers of the difficulty it plays. Keys are read from
+// the terminal in raw mode.
+package tui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/brantleyr/go-snake/rules"
+)
+
+const (
+	clearScreen = "\x1b[2J"
+	cursorHome  = "\x1b[H"
+	hideCursor  = "\x1b[?25l"
+	showCursor  = "\x1b[?25h"
+	reset       = "\x1b[0m"
+	borderColor = "#005500"
+	appleColor  = "#ff0000"
+	textColor   = "#8c8c8c"
+)
+
+var headGlyphs = map[string]string{
+	rules.Up:    "▲ ",
+	rules.Down:  "▼ ",
+	rules.Left:  "◀ ",
+	rules.Right: "▶ ",
+}
+
+type Options struct {
+	Profile  rules.Profile // the difficulty, its curve and color tiers
+	Seed     int64
+	Bot      bool // let a simple bot play, for smoke tests
+	MaxTicks int  // quit after this many moves, 0 plays until the player quits
+	In       *os.File
+	Out      io.Writer
+}
+
+type key int
+
+const (
+	keyNone key = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyPause
+	keyEnter
+	keyQuit
+)
+
+// ErrNoTerminal is returned when there are no keys to read and no bot playing
+var ErrNoTerminal = errors.New("stdin isn't a terminal, run it in one or pass -bot to let a bot play")
+
+// Run plays until the player quits, or the bot's game ends
+func Run(opts Options) error {
+	out := bufio.NewWriter(opts.Out)
+	keys := make(chan key, 16)
+
+	// Raw mode only makes sense on a terminal, a bot in CI has none, and
+	// without either nothing would ever end the game
+	terminal := term.IsTerminal(int(opts.In.Fd()))
+	if !terminal && !opts.Bot {
+		return ErrNoTerminal
+	}
+	if terminal {
+		state, err := term.MakeRaw(int(opts.In.Fd()))
+		if err != nil {
+			return err
+		}
+		defer term.Restore(int(opts.In.Fd()), state)
+		go readKeys(opts.In, keys)
+	}
+	fmt.Fprint(out, clearScreen+hideCursor)
+	defer func() {
+		fmt.Fprint(out, reset+showCursor+"\r\n")
+		out.Flush()
+	}()
+
+	seed := opts.Seed
+	newArena := func() *rules.Arena {
+		arena := rules.NewArena(rules.DefaultWidth, rules.DefaultHeight, seed, 1)
+		arena.SetCurve(opts.Profile.Curve)
+		return arena
+	}
+	arena := newArena()
+	paused := false
+	timer := time.NewTimer(time.Duration(arena.MoveInterval()) * time.Millisecond)
+	defer timer.Stop()
+
+	for {
+		draw(out, arena, opts.Profile, paused, opts.Bot)
+		if err := out.Flush(); err != nil {
+			return err
+		}
+		if opts.MaxTicks > 0 && arena.Tick >= opts.MaxTicks {
+			return nil
+		}
+		if opts.Bot && arena.Over() {
+			return nil
+		}
+
+		select {
+		case k := <-keys:
+			switch k {
+			case keyQuit:
+				return nil
+			case keyPause:
+				paused = !paused
+			case keyEnter:
+				if arena.Over() {
+					seed++
+					arena = newArena()
+				}
+			case keyUp:
+				arena.Turn(0, rules.Up)
+			case keyDown:
+				arena.Turn(0, rules.Down)
+			case keyLeft:
+				arena.Turn(0, rules.Left)
+			case keyRight:
+				arena.Turn(0, rules.Right)
+			}
+		case <-timer.C:
+			if !paused && !arena.Over() {
+				if opts.Bot {
+					arena.Turn(0, botMove(arena))
+				}
+				arena.Step()
+			}
+			timer.Reset(time.Duration(arena.MoveInterval()) * time.Millisecond)
+		}
+	}
+}
+
+// readKeys turns raw bytes into keys, arrow keys arriving as ESC [ A to D
+func readKeys(in io.Reader, keys chan<- key) {
+	reader := bufio.NewReader(in)
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			keys <- keyQuit
+			return
+		}
+		k := keyNone
+		switch b {
+		case 'w', 'W':
+			k = keyUp
+		case 's', 'S':
+			k = keyDown
+		case 'a', 'A':
+			k = keyLeft
+		case 'd', 'D':
+			k = keyRight
+		case 'p', 'P', ' ':
+			k = keyPause
+		case '\r', '\n':
+			k = keyEnter
+		case 'q', 'Q', 3: // 3 is Ctrl+C, which raw mode hands to us
+			k = keyQuit
+		case 0x1b:
+			if next, _ := reader.ReadByte(); next == '[' {
+				arrow, _ := reader.ReadByte()
+				k = map[byte]key{'A': keyUp, 'B': keyDown, 'C': keyRight, 'D': keyLeft}[arrow]
+			}
+		}
+		if k != keyNone {
+			keys <- k
+		}
+	}
+}
+
+func draw(out io.Writer, arena *rules.Arena, profile rules.Profile, paused bool, bot bool) {
+	snake := arena.Snakes[0]
+	cells := map[rules.Point]string{}
+	if snake.Alive {
+		color := profile.Tier(arena.Ramp.Shown).Color
+		for idx, cell := range snake.Body {
+			if idx == 0 {
+				cells[cell] = fg(color) + headGlyphs[snake.Direction]
+			} else {
+				cells[cell] = fg(color) + "■ "
+			}
+		}
+	}
+	if arena.InBounds(arena.Apple) {
+		cells[arena.Apple] = fg(appleColor) + "● "
+	}
+
+	var b strings.Builder
+	b.WriteString(cursorHome)
+	fmt.Fprintf(&b, "%sGo Snake   %sScore: %d   Speed: %d%s\x1b[K\r\n", fg("#749e35"), reset, snake.Score, arena.Ramp.Shown, reset)
+	b.WriteString(fg(borderColor) + "┌" + strings.Repeat("─", arena.Width*2) + "┐" + reset + "\r\n")
+	for y := 0; y < arena.Height; y++ {
+		b.WriteString(fg(borderColor) + "│" + reset)
+		for x := 0; x < arena.Width; x++ {
+			if glyph, ok := cells[rules.Point{X: x, Y: y}]; ok {
+				b.WriteString(glyph + reset)
+			} else {
+				b.WriteString("  ")
+			}
+		}
+		b.WriteString(fg(borderColor) + "│" + reset + "\r\n")
+	}
+	b.WriteString(fg(borderColor) + "└" + strings.Repeat("─", arena.Width*2) + "┘" + reset + "\r\n")
+
+	status := "Arrows/WASD move   P pause   Q quit"
+	switch {
+	case bot && arena.Over():
+		status = fmt.Sprintf("Bot finished with %d", snake.Score)
+	case arena.Over():
+		status = "Womp womp. Game over.   Enter new game   Q quit"
+	case paused:
+		status = "Game paused.   P resume   Q quit"
+	}
+	b.WriteString(fg(textColor) + status + reset + "\x1b[K\r\n")
+	io.WriteString(out, b.String())
+}
+
+// fg is the 24 bit color escape for a hex color like "#8bc03c"
+func fg(hex string) string {
+	var r, g, b uint8
+	fmt.Sscanf(hex, "#%02x%02x%02x", &r, &g, &b)
+	return fmt.Sprintf("\x1b[38;2;%d;%d;%dm", r, g, b)
+}
+
+// botMove heads for the apple without running into anything it can see
+func botMove(arena *rules.Arena) string {
+	snake := arena.Snakes[0]
+	best := snake.Direction
+	bestDistance := -1
+	for _, direction := range []string{rules.Up, rules.Down, rules.Left, rules.Right} {
+		next := arena.Next(snake.Head(), direction)
+		if !arena.InBounds(next) || arena.Occupied(next) {
+			continue
+		}
+		distance := abs(next.X-arena.Apple.X) + abs(next.Y-arena.Apple.Y)
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = direction, distance
+		}
+	}
+	return best
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
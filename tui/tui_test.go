@@ -0,0 +1,59 @@
+package tui
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/brantleyr/go-snake/rules"
+)
+
+var normal = rules.Profile{ID: "normal", Curve: rules.NormalCurve, OrangeAt: 4, RedAt: 7}
+
+// notATerminal is stdin piped in from somewhere, like CI
+func notATerminal(t *testing.T) *os.File {
+	t.Helper()
+	in, out, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		in.Close()
+		out.Close()
+	})
+	return in
+}
+
+func TestPlayerNeedsATerminal(t *testing.T) {
+	var out bytes.Buffer
+	err := Run(Options{Profile: normal, Seed: 1, In: notATerminal(t), Out: &out})
+	if err != ErrNoTerminal {
+		t.Fatalf("got %v, want ErrNoTerminal", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("drew %q before giving up", out.String())
+	}
+}
+
+func TestBotPlaysWithoutATerminal(t *testing.T) {
+	var out bytes.Buffer
+	if err := Run(Options{Profile: normal, Seed: 1, Bot: true, MaxTicks: 2, In: notATerminal(t), Out: &out}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Score: 0") {
+		t.Errorf("no score line in %q", out.String())
+	}
+}
+
+func TestSnakeTakesTheProfilesColor(t *testing.T) {
+	arena := rules.NewArena(rules.DefaultWidth, rules.DefaultHeight, 1, 1)
+	for _, shown := range []int{1, 4, 7} {
+		arena.Ramp.Shown = shown
+		var out strings.Builder
+		draw(&out, arena, normal, false, false)
+		if want := fg(normal.Tier(shown).Color) + headGlyphs[rules.Down]; !strings.Contains(out.String(), want) {
+			t.Errorf("speed %d: the head isn't drawn in %s", shown, normal.Tier(shown).Name)
+		}
+	}
+}